@@ -0,0 +1,203 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// ADFNode is a node of Atlassian Document Format used by JIRA Cloud REST API v3
+// https://developer.atlassian.com/cloud/jira/platform/apis/document/structure/
+type ADFNode struct {
+	Type    string         `json:"type"`
+	Version int            `json:"version,omitempty"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Content []ADFNode      `json:"content,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Marks   []ADFMark      `json:"marks,omitempty"`
+}
+
+type ADFMark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+var (
+	adfHeadingRegexp   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	adfBulletRegexp    = regexp.MustCompile(`^\s*[-*]\s+(.*)$`)
+	adfSeparatorRegexp = regexp.MustCompile(`^\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*$`)
+	adfInlineRegexp    = regexp.MustCompile("\\[([^\\]]+)\\]\\((https?://[^)\\s]+)\\)|`([^`]+)`|\\*\\*([^*]+)\\*\\*|(https?://[^\\s|)]+)")
+)
+
+// MarkdownToADF converts the Markdown subset used by report templates
+// (headings, paragraphs, bullet lists, tables, fenced code blocks, links,
+// inline code and bold text) into an ADF document
+func MarkdownToADF(text string) ADFNode {
+	doc := ADFNode{Type: "doc", Version: 1, Content: []ADFNode{}}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			doc.Content = append(doc.Content, adfParagraph(paragraph))
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			doc.Content = append(doc.Content, adfCodeBlock(strings.TrimPrefix(trimmed, "```"), code))
+
+		case strings.HasPrefix(trimmed, "|"):
+			flush()
+
+			var rows []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				rows = append(rows, strings.TrimSpace(lines[i]))
+			}
+			i--
+			doc.Content = append(doc.Content, adfTable(rows))
+
+		case adfHeadingRegexp.MatchString(trimmed):
+			flush()
+
+			match := adfHeadingRegexp.FindStringSubmatch(trimmed)
+			doc.Content = append(doc.Content, ADFNode{
+				Type:    "heading",
+				Attrs:   map[string]any{"level": len(match[1])},
+				Content: adfInline(match[2]),
+			})
+
+		case adfBulletRegexp.MatchString(line):
+			flush()
+
+			list := ADFNode{Type: "bulletList"}
+			for ; i < len(lines) && adfBulletRegexp.MatchString(lines[i]); i++ {
+				item := adfBulletRegexp.FindStringSubmatch(lines[i])[1]
+				list.Content = append(list.Content, ADFNode{
+					Type:    "listItem",
+					Content: []ADFNode{adfParagraph([]string{item})},
+				})
+			}
+			i--
+			doc.Content = append(doc.Content, list)
+
+		case trimmed == "":
+			flush()
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+
+	flush()
+
+	return doc
+}
+
+// adfParagraph joins lines of a paragraph with hard breaks
+func adfParagraph(lines []string) ADFNode {
+	paragraph := ADFNode{Type: "paragraph"}
+
+	for i, line := range lines {
+		if i > 0 {
+			paragraph.Content = append(paragraph.Content, ADFNode{Type: "hardBreak"})
+		}
+		paragraph.Content = append(paragraph.Content, adfInline(line)...)
+	}
+
+	return paragraph
+}
+
+func adfCodeBlock(language string, lines []string) ADFNode {
+	block := ADFNode{Type: "codeBlock"}
+
+	if language = strings.TrimSpace(language); language != "" {
+		block.Attrs = map[string]any{"language": language}
+	}
+
+	if code := strings.Join(lines, "\n"); code != "" {
+		block.Content = []ADFNode{{Type: "text", Text: code}}
+	}
+
+	return block
+}
+
+// adfTable treats the first row as a header when it's followed by a
+// separator row (|---|---|)
+func adfTable(rows []string) ADFNode {
+	table := ADFNode{Type: "table"}
+	header := len(rows) > 1 && adfSeparatorRegexp.MatchString(rows[1])
+
+	for i, row := range rows {
+		if adfSeparatorRegexp.MatchString(row) {
+			continue
+		}
+
+		cellType := "tableCell"
+		if header && i == 0 {
+			cellType = "tableHeader"
+		}
+
+		tableRow := ADFNode{Type: "tableRow"}
+		for _, cell := range strings.Split(strings.Trim(row, "|"), "|") {
+			tableRow.Content = append(tableRow.Content, ADFNode{
+				Type:    cellType,
+				Content: []ADFNode{adfParagraph([]string{strings.TrimSpace(cell)})},
+			})
+		}
+		table.Content = append(table.Content, tableRow)
+	}
+
+	return table
+}
+
+// adfInline converts links, inline code and bold text into marked text nodes
+func adfInline(text string) []ADFNode {
+	var nodes []ADFNode
+
+	addText := func(text string, marks ...ADFMark) {
+		if text != "" {
+			nodes = append(nodes, ADFNode{Type: "text", Text: text, Marks: marks})
+		}
+	}
+
+	last := 0
+	for _, match := range adfInlineRegexp.FindAllStringSubmatchIndex(text, -1) {
+		addText(text[last:match[0]])
+		last = match[1]
+
+		switch {
+		case match[2] != -1:
+			addText(text[match[2]:match[3]], ADFMark{Type: "link", Attrs: map[string]any{"href": text[match[4]:match[5]]}})
+		case match[6] != -1:
+			addText(text[match[6]:match[7]], ADFMark{Type: "code"})
+		case match[8] != -1:
+			// code mark can't be combined with other formatting
+			for _, node := range adfInline(text[match[8]:match[9]]) {
+				if !slices.ContainsFunc(node.Marks, func(m ADFMark) bool { return m.Type == "code" }) {
+					node.Marks = append(node.Marks, ADFMark{Type: "strong"})
+				}
+				nodes = append(nodes, node)
+			}
+		default:
+			url := text[match[10]:match[11]]
+			addText(url, ADFMark{Type: "link", Attrs: map[string]any{"href": url}})
+		}
+	}
+	addText(text[last:])
+
+	return nodes
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownToADF(t *testing.T) {
+	doc := MarkdownToADF("## Flaky examples\nFailed build: https://ci.example.com/1\nsee **[docs](https://example.com)**\n\n| Example | Status |\n|---|---|\n| `./spec/flaky_spec.rb[1:1]` | failed |\n\n- first\n- second\n\n```ruby\nexpect(1).to eq(2)\n```\n")
+
+	assert.Equal(t, "doc", doc.Type)
+	assert.Equal(t, 1, doc.Version)
+	assert.Equal(t, []string{"heading", "paragraph", "table", "bulletList", "codeBlock"}, adfTypes(doc.Content))
+
+	paragraph := doc.Content[1]
+	assert.Equal(t, []string{"text", "text", "hardBreak", "text", "text"}, adfTypes(paragraph.Content))
+	assert.Equal(t, ADFMark{Type: "link", Attrs: map[string]any{"href": "https://ci.example.com/1"}}, paragraph.Content[1].Marks[0])
+	assert.Equal(t, ADFNode{Type: "text", Text: "docs", Marks: []ADFMark{
+		{Type: "link", Attrs: map[string]any{"href": "https://example.com"}},
+		{Type: "strong"},
+	}}, paragraph.Content[4])
+
+	table := doc.Content[2]
+	assert.Equal(t, 2, len(table.Content))
+	assert.Equal(t, "tableHeader", table.Content[0].Content[0].Type)
+	cell := table.Content[1].Content[0]
+	assert.Equal(t, "tableCell", cell.Type)
+	assert.Equal(t, ADFNode{Type: "text", Text: "./spec/flaky_spec.rb[1:1]", Marks: []ADFMark{{Type: "code"}}}, cell.Content[0].Content[0])
+
+	assert.Equal(t, 2, len(doc.Content[3].Content))
+
+	code := doc.Content[4]
+	assert.Equal(t, map[string]any{"language": "ruby"}, code.Attrs)
+	assert.Equal(t, "expect(1).to eq(2)", code.Content[0].Text)
+}
+
+func TestMarkdownToADFJSON(t *testing.T) {
+	data, err := json.Marshal(MarkdownToADF("Flaky: `spec`"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "doc",
+		"version": 1,
+		"content": [{
+			"type": "paragraph",
+			"content": [
+				{"type": "text", "text": "Flaky: "},
+				{"type": "text", "text": "spec", "marks": [{"type": "code"}]}
+			]
+		}]
+	}`, string(data))
+}
+
+func adfTypes(nodes []ADFNode) []string {
+	var types []string
+	for _, node := range nodes {
+		types = append(types, node.Type)
+	}
+	return types
+}
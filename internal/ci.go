@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+var pullRequestRefRegexp = regexp.MustCompile(`^refs/pull/(\d+)/`)
+var pullRequestURLRegexp = regexp.MustCompile(`/pull/(\d+)/?$`)
+var gitlabParallelSuffixRegexp = regexp.MustCompile(` \d+/\d+$`)
+
+const (
+	ProviderGithubActions = "github-actions"
+	ProviderCircleCI      = "circleci"
+	ProviderGitlabCI      = "gitlab-ci"
+	ProviderBuildkite     = "buildkite"
+	ProviderJenkins       = "jenkins"
+)
+
+// Build describes the CI build rspec-sanity runs in, available in templates
+// under .Build so one configuration works across CI providers.
+type Build struct {
+	Provider    string `json:"provider,omitempty"`
+	ID          string `json:"id,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+	CommitSHA   string `json:"commit_sha,omitempty"`
+	Repository  string `json:"repository,omitempty"`
+	NodeIndex   int    `json:"node_index"`
+	NodeTotal   int    `json:"node_total"`
+	PullRequest int    `json:"pull_request,omitempty"`
+	JobName     string `json:"job_name,omitempty"`
+}
+
+// DetectBuild fills build metadata from env variables of CircleCI, GitHub
+// Actions, GitLab CI, Buildkite or Jenkins. NodeIndex is always 0-based.
+func DetectBuild() Build {
+	build := Build{
+		NodeTotal:   1,
+		CommitSHA:   DetectCommitSHA(),
+		Repository:  DetectRepository(),
+		PullRequest: DetectPullRequestNumber(),
+	}
+
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		build.Provider = ProviderGithubActions
+		build.ID = os.Getenv("GITHUB_RUN_ID")
+		if build.ID != "" && os.Getenv("GITHUB_SERVER_URL") != "" {
+			build.URL = os.Getenv("GITHUB_SERVER_URL") + "/" + os.Getenv("GITHUB_REPOSITORY") + "/actions/runs/" + build.ID
+		}
+		build.Branch = firstEnv("GITHUB_HEAD_REF", "GITHUB_REF_NAME")
+		build.JobName = os.Getenv("GITHUB_JOB")
+	case os.Getenv("CIRCLECI") == "true":
+		build.Provider = ProviderCircleCI
+		build.URL = os.Getenv("CIRCLE_BUILD_URL")
+		build.Branch = os.Getenv("CIRCLE_BRANCH")
+		build.NodeIndex = atoiEnv("CIRCLE_NODE_INDEX")
+		build.NodeTotal = max(atoiEnv("CIRCLE_NODE_TOTAL"), 1)
+		build.JobName = os.Getenv("CIRCLE_JOB")
+		// unlike the job id, workflow id doesn't change when failed jobs are rerun
+		build.ID = firstEnv("CIRCLE_WORKFLOW_ID", "CIRCLE_BUILD_NUM")
+		if os.Getenv("CIRCLE_WORKFLOW_ID") != "" && build.JobName != "" {
+			build.ID += "/" + build.JobName
+		}
+	case os.Getenv("GITLAB_CI") == "true":
+		build.Provider = ProviderGitlabCI
+		build.URL = os.Getenv("CI_JOB_URL")
+		build.Branch = firstEnv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", "CI_COMMIT_REF_NAME")
+		// CI_NODE_INDEX is 1-based
+		build.NodeIndex = max(atoiEnv("CI_NODE_INDEX")-1, 0)
+		build.NodeTotal = max(atoiEnv("CI_NODE_TOTAL"), 1)
+		build.JobName = os.Getenv("CI_JOB_NAME")
+		// job id changes on retries and differs between parallel nodes, which
+		// are named "rspec 1/3", "rspec 2/3"...
+		build.ID = os.Getenv("CI_JOB_ID")
+		if os.Getenv("CI_PIPELINE_ID") != "" && build.JobName != "" {
+			build.ID = os.Getenv("CI_PIPELINE_ID") + "/" + gitlabParallelSuffixRegexp.ReplaceAllString(build.JobName, "")
+		}
+		if build.PullRequest == 0 {
+			build.PullRequest = atoiEnv("CI_MERGE_REQUEST_IID")
+		}
+	case os.Getenv("BUILDKITE") == "true":
+		build.Provider = ProviderBuildkite
+		build.ID = os.Getenv("BUILDKITE_BUILD_ID")
+		build.URL = os.Getenv("BUILDKITE_BUILD_URL")
+		build.Branch = os.Getenv("BUILDKITE_BRANCH")
+		build.NodeIndex = atoiEnv("BUILDKITE_PARALLEL_JOB")
+		build.NodeTotal = max(atoiEnv("BUILDKITE_PARALLEL_JOB_COUNT"), 1)
+		build.JobName = os.Getenv("BUILDKITE_LABEL")
+	case os.Getenv("JENKINS_URL") != "":
+		build.Provider = ProviderJenkins
+		build.URL = os.Getenv("BUILD_URL")
+		build.Branch = firstEnv("CHANGE_BRANCH", "BRANCH_NAME", "GIT_BRANCH")
+		build.JobName = os.Getenv("JOB_NAME")
+		// stages restarted within a build keep the build number
+		build.ID = os.Getenv("BUILD_NUMBER")
+		if build.ID != "" && build.JobName != "" {
+			build.ID = build.JobName + "/" + build.ID
+		}
+	}
+
+	return build
+}
+
+// DetectPullRequestNumber tries to find the number of the pull request
+// being built using env variables exposed by the common CI providers.
+// Returns 0 when the build is not associated with a pull request.
+func DetectPullRequestNumber() int {
+	if number := atoiEnv("RSPEC_SANITY_PR_NUMBER"); number > 0 {
+		return number
+	}
+
+	// GitHub Actions
+	if match := pullRequestRefRegexp.FindStringSubmatch(os.Getenv("GITHUB_REF")); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		return number
+	}
+
+	// CircleCI
+	if match := pullRequestURLRegexp.FindStringSubmatch(os.Getenv("CIRCLE_PULL_REQUEST")); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		return number
+	}
+
+	// Buildkite, Jenkins, Travis
+	for _, key := range []string{"BUILDKITE_PULL_REQUEST", "CHANGE_ID", "TRAVIS_PULL_REQUEST"} {
+		if number := atoiEnv(key); number > 0 {
+			return number
+		}
+	}
+
+	return 0
+}
+
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func atoiEnv(key string) int {
+	number, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return 0
+	}
+
+	return number
+}
+
+// DetectCommitSHA returns the commit being built according to the CI env
+// variables, empty string when it can't be detected.
+func DetectCommitSHA() string {
+	for _, key := range []string{"RSPEC_SANITY_COMMIT_SHA", "GITHUB_SHA", "CIRCLE_SHA1", "BUILDKITE_COMMIT", "CI_COMMIT_SHA", "GIT_COMMIT", "TRAVIS_COMMIT"} {
+		if sha := os.Getenv(key); sha != "" {
+			return sha
+		}
+	}
+
+	return ""
+}
+
+// DetectHeadSHA returns the head commit of the pull request being built -
+// on pull_request events GITHUB_SHA is a synthetic merge commit, so GitHub
+// Actions builds take it from the event payload instead. Falls back to
+// DetectCommitSHA.
+func DetectHeadSHA() string {
+	if sha := os.Getenv("RSPEC_SANITY_HEAD_SHA"); sha != "" {
+		return sha
+	}
+
+	if path := os.Getenv("GITHUB_EVENT_PATH"); path != "" {
+		var event struct {
+			PullRequest struct {
+				Head struct {
+					SHA string `json:"sha"`
+				} `json:"head"`
+			} `json:"pull_request"`
+		}
+
+		data, err := os.ReadFile(path)
+		if err == nil && json.Unmarshal(data, &event) == nil && event.PullRequest.Head.SHA != "" {
+			return event.PullRequest.Head.SHA
+		}
+	}
+
+	return DetectCommitSHA()
+}
+
+// DetectRepository returns "owner/repo" of the Github repository being built,
+// empty string when it can't be detected.
+func DetectRepository() string {
+	if repository := os.Getenv("RSPEC_SANITY_GITHUB_REPOSITORY"); repository != "" {
+		return repository
+	}
+
+	// GitHub Actions
+	if repository := os.Getenv("GITHUB_REPOSITORY"); repository != "" {
+		return repository
+	}
+
+	// CircleCI
+	if os.Getenv("CIRCLE_PROJECT_USERNAME") != "" && os.Getenv("CIRCLE_PROJECT_REPONAME") != "" {
+		return os.Getenv("CIRCLE_PROJECT_USERNAME") + "/" + os.Getenv("CIRCLE_PROJECT_REPONAME")
+	}
+
+	return ""
+}
@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectPullRequestNumber(t *testing.T) {
+	for _, key := range []string{"RSPEC_SANITY_PR_NUMBER", "GITHUB_REF", "CIRCLE_PULL_REQUEST", "BUILDKITE_PULL_REQUEST", "CHANGE_ID", "TRAVIS_PULL_REQUEST"} {
+		t.Setenv(key, "")
+	}
+
+	assert.Equal(t, 0, DetectPullRequestNumber())
+
+	t.Setenv("BUILDKITE_PULL_REQUEST", "false")
+	assert.Equal(t, 0, DetectPullRequestNumber())
+
+	t.Setenv("CIRCLE_PULL_REQUEST", "https://github.com/rwojsznis/rspec-sanity/pull/42")
+	assert.Equal(t, 42, DetectPullRequestNumber())
+
+	t.Setenv("GITHUB_REF", "refs/pull/7/merge")
+	assert.Equal(t, 7, DetectPullRequestNumber())
+
+	t.Setenv("RSPEC_SANITY_PR_NUMBER", "3")
+	assert.Equal(t, 3, DetectPullRequestNumber())
+}
+
+func clearBuildEnv(t *testing.T) {
+	for _, key := range []string{
+		"GITHUB_ACTIONS", "CIRCLECI", "CIRCLE_WORKFLOW_ID", "CIRCLE_JOB", "GITLAB_CI", "BUILDKITE", "JENKINS_URL",
+		"RSPEC_SANITY_PR_NUMBER", "RSPEC_SANITY_COMMIT_SHA", "RSPEC_SANITY_GITHUB_REPOSITORY",
+		"GITHUB_REF", "GITHUB_SHA", "GITHUB_REPOSITORY", "CIRCLE_PULL_REQUEST", "CIRCLE_SHA1",
+		"CIRCLE_PROJECT_USERNAME", "CIRCLE_PROJECT_REPONAME", "BUILDKITE_PULL_REQUEST",
+		"BUILDKITE_COMMIT", "CHANGE_ID", "TRAVIS_PULL_REQUEST", "CI_COMMIT_SHA", "GIT_COMMIT", "TRAVIS_COMMIT",
+		"RSPEC_SANITY_HEAD_SHA", "GITHUB_EVENT_PATH", "CIRCLE_BUILD_NUM", "CI_JOB_ID", "CI_PIPELINE_ID", "CI_JOB_NAME",
+		"BUILD_TAG", "BUILD_NUMBER", "JOB_NAME",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestDetectBuild(t *testing.T) {
+	clearBuildEnv(t)
+
+	assert.Equal(t, Build{NodeTotal: 1}, DetectBuild())
+
+	t.Setenv("CIRCLECI", "true")
+	t.Setenv("CIRCLE_WORKFLOW_ID", "workflow-1")
+	t.Setenv("CIRCLE_BUILD_NUM", "1")
+	t.Setenv("CIRCLE_BUILD_URL", "https://circleci.com/gh/jdoe/app/1")
+	t.Setenv("CIRCLE_BRANCH", "main")
+	t.Setenv("CIRCLE_SHA1", "abc123")
+	t.Setenv("CIRCLE_PROJECT_USERNAME", "jdoe")
+	t.Setenv("CIRCLE_PROJECT_REPONAME", "app")
+	t.Setenv("CIRCLE_NODE_INDEX", "2")
+	t.Setenv("CIRCLE_NODE_TOTAL", "4")
+	t.Setenv("CIRCLE_PULL_REQUEST", "https://github.com/jdoe/app/pull/5")
+	t.Setenv("CIRCLE_JOB", "rspec")
+
+	assert.Equal(t, Build{
+		Provider:    ProviderCircleCI,
+		ID:          "workflow-1/rspec",
+		URL:         "https://circleci.com/gh/jdoe/app/1",
+		Branch:      "main",
+		CommitSHA:   "abc123",
+		Repository:  "jdoe/app",
+		NodeIndex:   2,
+		NodeTotal:   4,
+		PullRequest: 5,
+		JobName:     "rspec",
+	}, DetectBuild())
+
+	// rerun of failed jobs
+	t.Setenv("CIRCLE_BUILD_NUM", "2")
+	t.Setenv("CIRCLE_BUILD_URL", "https://circleci.com/gh/jdoe/app/2")
+	assert.Equal(t, "circleci/workflow-1/rspec", BuildKey(DetectBuild()))
+
+	// jobs outside of workflows
+	t.Setenv("CIRCLE_WORKFLOW_ID", "")
+	assert.Equal(t, "circleci/2", BuildKey(DetectBuild()))
+}
+
+func TestDetectBuildGitlab(t *testing.T) {
+	clearBuildEnv(t)
+
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_JOB_ID", "42")
+	t.Setenv("CI_NODE_INDEX", "1")
+	t.Setenv("CI_NODE_TOTAL", "3")
+	t.Setenv("CI_MERGE_REQUEST_IID", "8")
+
+	build := DetectBuild()
+	assert.Equal(t, ProviderGitlabCI, build.Provider)
+	assert.Equal(t, "42", build.ID)
+	assert.Equal(t, 0, build.NodeIndex)
+	assert.Equal(t, 3, build.NodeTotal)
+	assert.Equal(t, 8, build.PullRequest)
+
+	t.Setenv("CI_PIPELINE_ID", "7")
+	t.Setenv("CI_JOB_NAME", "rspec 1/3")
+	build = DetectBuild()
+	assert.Equal(t, "7/rspec", build.ID)
+	assert.Equal(t, "rspec 1/3", build.JobName)
+
+	// retry of the job
+	t.Setenv("CI_JOB_ID", "43")
+	assert.Equal(t, "gitlab-ci/7/rspec", BuildKey(DetectBuild()))
+
+	// another parallel node
+	t.Setenv("CI_JOB_ID", "44")
+	t.Setenv("CI_NODE_INDEX", "2")
+	t.Setenv("CI_JOB_NAME", "rspec 2/3")
+	build = DetectBuild()
+	assert.Equal(t, "gitlab-ci/7/rspec", BuildKey(build))
+	assert.Equal(t, 1, build.NodeIndex)
+}
+
+func TestDetectBuildJenkins(t *testing.T) {
+	clearBuildEnv(t)
+
+	t.Setenv("JENKINS_URL", "https://jenkins.example.com/")
+	t.Setenv("BUILD_TAG", "jenkins-app-rspec-12")
+	t.Setenv("BUILD_NUMBER", "12")
+	t.Setenv("JOB_NAME", "app/rspec")
+
+	build := DetectBuild()
+	assert.Equal(t, ProviderJenkins, build.Provider)
+	assert.Equal(t, "app/rspec/12", build.ID)
+	assert.Equal(t, "app/rspec", build.JobName)
+
+	t.Setenv("JOB_NAME", "")
+	assert.Equal(t, "jenkins/12", BuildKey(DetectBuild()))
+}
+
+func TestDetectHeadSHA(t *testing.T) {
+	clearBuildEnv(t)
+
+	assert.Equal(t, "", DetectHeadSHA())
+
+	t.Setenv("GITHUB_SHA", "merge-sha")
+	assert.Equal(t, "merge-sha", DetectHeadSHA())
+
+	// push events carry no pull request
+	event := filepath.Join(t.TempDir(), "event.json")
+	assert.NoError(t, os.WriteFile(event, []byte(`{"after": "merge-sha"}`), 0644))
+	t.Setenv("GITHUB_EVENT_PATH", event)
+	assert.Equal(t, "merge-sha", DetectHeadSHA())
+
+	assert.NoError(t, os.WriteFile(event, []byte(`{"pull_request": {"head": {"sha": "head-sha"}}}`), 0644))
+	assert.Equal(t, "head-sha", DetectHeadSHA())
+
+	t.Setenv("RSPEC_SANITY_HEAD_SHA", "override-sha")
+	assert.Equal(t, "override-sha", DetectHeadSHA())
+}
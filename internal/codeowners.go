@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type CodeOwners struct {
+	rules []codeownersRule
+}
+
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// LoadCodeOwners parses CODEOWNERS file from the given path or, when path is
+// empty, from the first of the default locations Github looks at.
+func LoadCodeOwners(path string) (*CodeOwners, error) {
+	if path == "" {
+		for _, location := range codeownersLocations {
+			if _, err := os.Stat(location); err == nil {
+				path = location
+				break
+			}
+		}
+	}
+
+	if path == "" {
+		return &CodeOwners{}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseCodeOwners(file)
+}
+
+func ParseCodeOwners(file io.Reader) (*CodeOwners, error) {
+	co := &CodeOwners{}
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		pattern, err := codeownersPattern(fields[0])
+		if err != nil {
+			return nil, err
+		}
+
+		co.rules = append(co.rules, codeownersRule{
+			pattern: pattern,
+			owners:  fields[1:],
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return co, nil
+}
+
+// Owners returns owners of the given (repository relative) path - as in Github
+// the last matching rule takes precedence.
+func (co *CodeOwners) Owners(path string) []string {
+	path = strings.TrimPrefix(path, "./")
+
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].pattern.MatchString(path) {
+			return co.rules[i].owners
+		}
+	}
+
+	return nil
+}
+
+// codeownersPattern translates gitignore-style pattern into a regexp
+func codeownersPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var sb strings.Builder
+
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("(^|/)")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**/") {
+				sb.WriteString("(.*/)?")
+				i += 2
+			} else if strings.HasPrefix(pattern[i:], "**") {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if directory {
+		sb.WriteString("/")
+	} else {
+		sb.WriteString("(/|$)")
+	}
+
+	return regexp.Compile(sb.String())
+}
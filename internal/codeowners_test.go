@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOwners(t *testing.T) {
+	data := `
+# comment
+*                    @org/everyone
+/spec/models/        @org/backend
+spec/**/*_system.rb  @org/frontend @jdoe
+payments             @org/payments
+`
+
+	co, err := ParseCodeOwners(strings.NewReader(data))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"@org/everyone"}, co.Owners("./spec/lib/foo_spec.rb"))
+	assert.Equal(t, []string{"@org/backend"}, co.Owners("./spec/models/user_spec.rb"))
+	assert.Equal(t, []string{"@org/frontend", "@jdoe"}, co.Owners("spec/features/login_system.rb"))
+	assert.Equal(t, []string{"@org/payments"}, co.Owners("./spec/payments/charge_spec.rb"))
+
+	assert.Nil(t, (&CodeOwners{}).Owners("spec/models/user_spec.rb"))
+}
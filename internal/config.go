@@ -2,11 +2,10 @@ package internal
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"os"
 	"strings"
-	"text/template"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -16,11 +15,32 @@ type Config struct {
 	Arguments       string        `toml:"arguments,omitempty"`
 	RerunArguments  string        `toml:"rerun_arguments,omitempty"`
 	PersistenceFile string        `toml:"persistence_file,omitempty"`
+	GroupBy         string        `toml:"group_by,omitempty"`
+	CodeownersFile  string        `toml:"codeowners_file,omitempty"`
+	Concurrency     int           `toml:"concurrency,omitempty"`
+	TemplatesDir    string        `toml:"templates_dir,omitempty"`
+	EnvAllowlist    []string      `toml:"env_allowlist,omitempty"`
+	EnvDenylist     []string      `toml:"env_denylist,omitempty"`
+	ReportTimeout   string        `toml:"report_timeout,omitempty"`
+	SpoolDir        string        `toml:"spool_dir,omitempty"`
+	ProjectRoot     string        `toml:"project_root,omitempty"`
 	Github          *GithubConfig `toml:"github,omitempty"`
 	Jira            *JiraConfig   `toml:"jira,omitempty"`
+	File            *FileConfig   `toml:"file,omitempty"`
+	reportTimeout   time.Duration
 }
 
 func LoadConfig(path string) (*Config, error) {
+	return loadConfig(path, true)
+}
+
+// LoadConfigWithoutCredentials loads and validates the config without
+// requiring reporters' credentials (eg. to preview templates locally)
+func LoadConfigWithoutCredentials(path string) (*Config, error) {
+	return loadConfig(path, false)
+}
+
+func loadConfig(path string, credentials bool) (*Config, error) {
 	_, err := os.Stat(path)
 
 	if err != nil {
@@ -41,15 +61,53 @@ config.example_status_persistence_file_path = 'spec/examples.txt'
 		`)
 	}
 
+	switch config.GroupBy {
+	case "", GroupByFile, GroupByExample, GroupByDirectory, GroupByOwner:
+	default:
+		return nil, fmt.Errorf(`unknown group_by value: "%s" (expected one of: file, example, directory, owner)`, config.GroupBy)
+	}
+
+	if config.Concurrency < 0 {
+		return nil, fmt.Errorf("concurrency can't be negative")
+	}
+
+	if config.ReportTimeout != "" {
+		config.reportTimeout, err = time.ParseDuration(config.ReportTimeout)
+		if err != nil || config.reportTimeout <= 0 {
+			return nil, fmt.Errorf(`invalid report_timeout: "%s" (expected a duration, eg. "5m")`, config.ReportTimeout)
+		}
+	}
+
 	if config.Github != nil {
-		err = config.Github.Prepare()
+		err = config.Github.Prepare(config.TemplateOptions())
 		if err != nil {
 			return nil, err
 		}
+
+		if credentials {
+			err = config.Github.LoadCredentials()
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if config.Jira != nil {
-		err = config.Jira.Prepare()
+		err = config.Jira.Prepare(config.TemplateOptions())
+		if err != nil {
+			return nil, err
+		}
+
+		if credentials {
+			err = config.Jira.LoadCredentials()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if config.File != nil {
+		err = config.File.Prepare()
 		if err != nil {
 			return nil, err
 		}
@@ -58,16 +116,66 @@ config.example_status_persistence_file_path = 'spec/examples.txt'
 	return config, err
 }
 
+// Root is the project root group keys are relative to - the working
+// directory (where rspec runs) unless project_root is set
+func (c *Config) Root() string {
+	if c.ProjectRoot == "" {
+		return "."
+	}
+
+	return c.ProjectRoot
+}
+
+func (c *Config) TemplateOptions() TemplateOptions {
+	return TemplateOptions{
+		Dir: c.TemplatesDir,
+		Env: EnvPolicy{
+			Allowlist: c.EnvAllowlist,
+			Denylist:  c.EnvDenylist,
+		},
+	}
+}
+
 func (c *Config) GetReporter() Reporter {
 	if c.Github != nil {
-		return NewGithubReporter(c.Github)
+		reporter := NewGithubReporter(c.Github)
+		reporter.reportTimeout = c.reportTimeout
+		return reporter
 	} else if c.Jira != nil {
-		return NewJiraReporter(c.Jira)
+		reporter := NewJiraReporter(c.Jira)
+		reporter.reportTimeout = c.reportTimeout
+		return reporter
+	} else if c.File != nil {
+		return NewFileReporter(c.File)
 	} else {
 		return &NullReporter{}
 	}
 }
 
+// GetSpool returns the spool of failed reports, nil when spool_dir isn't
+// configured
+func (c *Config) GetSpool() *Spool {
+	if c.SpoolDir == "" {
+		return nil
+	}
+
+	return NewSpool(c.SpoolDir)
+}
+
+func (c *Config) GroupFlakies(flakies []RspecExample) ([]FlakyGroup, error) {
+	owners := &CodeOwners{}
+
+	if c.GroupBy == GroupByOwner {
+		var err error
+		owners, err = LoadCodeOwners(c.CodeownersFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return GroupFlakies(flakies, c.GroupBy, owners)
+}
+
 func (c *Config) RunCommand(pattern []string) string {
 	var cmd []string
 	cmd = append(cmd, c.Command, c.Arguments)
@@ -117,42 +225,6 @@ func (c *Config) CollectExamples() ([]RspecExample, error) {
 	return examples, nil
 }
 
-type TemplateData struct {
-	Examples []RspecExample
-	Env      map[string]string
-}
-
-func RenderTemplate(customTemplate string, examples []RspecExample) (string, error) {
-	tmpl, err := new(template.Template).Parse(customTemplate)
-
-	if err != nil {
-		return "", err
-	}
-
-	var buf bytes.Buffer
-
-	env := os.Environ()
-	envMap := make(map[string]string)
-
-	for _, val := range env {
-		pair := strings.SplitN(val, "=", 2)
-		envMap[pair[0]] = pair[1]
-	}
-
-	data := TemplateData{
-		Examples: examples,
-		Env:      envMap,
-	}
-
-	err = tmpl.Execute(&buf, data)
-
-	if err != nil {
-		return "", err
-	}
-
-	return buf.String(), nil
-}
-
 func removeBlanks(s []string) []string {
 	var r []string
 	for _, str := range s {
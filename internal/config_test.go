@@ -33,6 +33,7 @@ persistence_file = "spec/examples.txt"
 	assert.Equal(t, "--format documentation --force-color", config.Arguments)
 	assert.Equal(t, "--format progress", config.RerunArguments)
 	assert.Equal(t, "spec/examples.txt", config.PersistenceFile)
+	assert.Equal(t, ".", config.Root())
 }
 
 func TestLoadConfigWithGithub(t *testing.T) {
@@ -130,6 +131,10 @@ func TestGetReporter(t *testing.T) {
 	config.Github = nil
 	config.Jira = &JiraConfig{}
 	assert.Equal(t, NewJiraReporter(config.Jira), config.GetReporter())
+
+	config.Jira = nil
+	config.File = &FileConfig{Path: "tmp/rspec-sanity.json"}
+	assert.Equal(t, NewFileReporter(config.File), config.GetReporter())
 }
 
 func TestRunCommand(t *testing.T) {
@@ -220,7 +225,7 @@ func TestRenderTemplate(t *testing.T) {
 
 	expected := fmt.Sprintf("Hello %s\n| foo |\n| bar |", os.Getenv("USER"))
 
-	result, err := RenderTemplate(template, examples)
+	result, err := RenderTemplate(template, FlakyGroup{Key: "foo", Examples: examples})
 
 	assert.NoError(t, err)
 	assert.Equal(
@@ -0,0 +1,18 @@
+package internal
+
+import (
+	"fmt"
+)
+
+type FileConfig struct {
+	Path         string `toml:"path,omitempty"`
+	MarkdownPath string `toml:"markdown_path,omitempty"`
+}
+
+func (fc *FileConfig) Prepare() error {
+	if fc.Path == "" {
+		return fmt.Errorf("no file reporter path specified in config")
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileReporter collects flaky groups in memory and writes them (together with
+// run metadata) as JSON - and optionally Markdown - once reporting is done.
+type FileReporter struct {
+	config *FileConfig
+	mu     sync.Mutex
+	groups []FileReportGroup
+}
+
+type FileReport struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	StatusCode  int               `json:"status_code"`
+	Attempts    int               `json:"attempts"`
+	Build       Build             `json:"build"`
+	Groups      []FileReportGroup `json:"groups"`
+}
+
+type FileReportGroup struct {
+	Key      string         `json:"key"`
+	Examples []RspecExample `json:"examples"`
+}
+
+func NewFileReporter(fc *FileConfig) *FileReporter {
+	return &FileReporter{
+		config: fc,
+	}
+}
+
+func (fr *FileReporter) Init() error {
+	for _, path := range []string{fr.config.Path, fr.config.MarkdownPath} {
+		if path == "" {
+			continue
+		}
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (fr *FileReporter) Verify(opts VerifyOptions) error {
+	log.Println("[file] Verifying reporter")
+
+	if !opts.CreateIssue {
+		checklist := NewChecklist("file")
+
+		for _, path := range []string{fr.config.Path, fr.config.MarkdownPath} {
+			if path == "" {
+				continue
+			}
+
+			checklist.Check(fmt.Sprintf("%s is writable", path), func() (string, error) {
+				file, err := os.CreateTemp(filepath.Dir(path), ".rspec-sanity-verify")
+				if err != nil {
+					return "", err
+				}
+				file.Close()
+				return "", os.Remove(file.Name())
+			})
+		}
+
+		return checklist.Err()
+	}
+
+	err := fr.ReportFlaky(verificationGroup)
+	if err != nil {
+		return err
+	}
+
+	err = fr.Finalize(&RunnerResult{Attempts: 2})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[file] Written test report: %s", fr.config.Path)
+
+	return nil
+}
+
+func (fr *FileReporter) ReportFlaky(group FlakyGroup) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	fr.groups = append(fr.groups, FileReportGroup{
+		Key:      group.Key,
+		Examples: group.Examples,
+	})
+
+	return nil
+}
+
+func (fr *FileReporter) Finalize(result *RunnerResult) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	// groups can be reported concurrently
+	sort.SliceStable(fr.groups, func(i, j int) bool {
+		return fr.groups[i].Key < fr.groups[j].Key
+	})
+
+	report := FileReport{
+		GeneratedAt: time.Now().UTC(),
+		StatusCode:  result.StatusCode,
+		Attempts:    result.Attempts,
+		Build:       DetectBuild(),
+		Groups:      fr.groups,
+	}
+
+	if report.Groups == nil {
+		report.Groups = make([]FileReportGroup, 0)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(fr.config.Path, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[file] Written report with %d group(s) to %s", len(report.Groups), fr.config.Path)
+
+	if fr.config.MarkdownPath != "" {
+		err = os.WriteFile(fr.config.MarkdownPath, []byte(report.Markdown()), 0644)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[file] Written markdown report to %s", fr.config.MarkdownPath)
+	}
+
+	return nil
+}
+
+func (r *FileReport) Markdown() string {
+	var sb strings.Builder
+
+	sb.WriteString("# rspec-sanity report\n\n")
+	fmt.Fprintf(&sb, "Generated at: %s\n", r.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(&sb, "Attempts: %d\n", r.Attempts)
+	fmt.Fprintf(&sb, "Exit code: %d\n", r.StatusCode)
+
+	if r.Build.URL != "" {
+		fmt.Fprintf(&sb, "Build: %s\n", r.Build.URL)
+	}
+
+	sb.WriteString("\n")
+
+	if len(r.Groups) == 0 {
+		sb.WriteString("No flaky examples found\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Group | Example |\n")
+	sb.WriteString("| --- | --- |\n")
+
+	for _, group := range r.Groups {
+		for _, example := range group.Examples {
+			fmt.Fprintf(&sb, "| %s | %s |\n", group.Key, example.Id)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileReporter(t *testing.T) {
+	dir := t.TempDir()
+
+	reporter := NewFileReporter(&FileConfig{
+		Path:         filepath.Join(dir, "reports", "flaky.json"),
+		MarkdownPath: filepath.Join(dir, "reports", "flaky.md"),
+	})
+
+	err := reporter.Init()
+	assert.NoError(t, err)
+
+	_, err = ReportFlakies(reporter, []FlakyGroup{
+		{
+			Key: "./spec/flaky_spec.rb",
+			Examples: []RspecExample{
+				{Id: "./spec/flaky_spec.rb[1:1]", Status: "failed"},
+				{Id: "./spec/flaky_spec.rb[1:2]", Status: "failed"},
+			},
+		},
+	}, 1)
+	assert.NoError(t, err)
+
+	err = FinalizeReporter(reporter, &RunnerResult{StatusCode: 0, Attempts: 2})
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "reports", "flaky.json"))
+	assert.NoError(t, err)
+
+	var report FileReport
+	err = json.Unmarshal(data, &report)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, report.Attempts)
+	assert.Equal(t, 1, len(report.Groups))
+	assert.Equal(t, "./spec/flaky_spec.rb", report.Groups[0].Key)
+	assert.Equal(t, "./spec/flaky_spec.rb[1:2]", report.Groups[0].Examples[1].Id)
+
+	markdown, err := os.ReadFile(filepath.Join(dir, "reports", "flaky.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(markdown), "| ./spec/flaky_spec.rb | ./spec/flaky_spec.rb[1:1] |")
+}
+
+func TestFileReporterConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flaky.json")
+	reporter := NewFileReporter(&FileConfig{Path: path})
+	assert.NoError(t, reporter.Init())
+
+	var groups []FlakyGroup
+	for _, key := range []string{"./spec/e_spec.rb", "./spec/c_spec.rb", "./spec/a_spec.rb", "./spec/d_spec.rb", "./spec/b_spec.rb"} {
+		groups = append(groups, FlakyGroup{Key: key, Examples: []RspecExample{{Id: key + "[1:1]", Status: "failed"}}})
+	}
+
+	_, err := ReportFlakies(reporter, groups, 4)
+	assert.NoError(t, err)
+
+	err = FinalizeReporter(reporter, &RunnerResult{StatusCode: 0, Attempts: 2})
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var report FileReport
+	err = json.Unmarshal(data, &report)
+	assert.NoError(t, err)
+
+	var keys []string
+	for _, group := range report.Groups {
+		keys = append(keys, group.Key)
+	}
+	assert.Equal(t, []string{"./spec/a_spec.rb", "./spec/b_spec.rb", "./spec/c_spec.rb", "./spec/d_spec.rb", "./spec/e_spec.rb"}, keys)
+}
+
+func TestFileReporterWithoutFlakies(t *testing.T) {
+	dir := t.TempDir()
+
+	reporter := NewFileReporter(&FileConfig{
+		Path:         filepath.Join(dir, "reports", "flaky.json"),
+		MarkdownPath: filepath.Join(dir, "reports", "flaky.md"),
+	})
+	assert.True(t, IsFinalizer(reporter))
+
+	assert.NoError(t, reporter.Init())
+
+	_, err := ReportFlakies(reporter, nil, 1)
+	assert.NoError(t, err)
+
+	err = FinalizeReporter(reporter, &RunnerResult{StatusCode: 0, Attempts: 1})
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "reports", "flaky.json"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"groups": []`)
+
+	markdown, err := os.ReadFile(filepath.Join(dir, "reports", "flaky.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(markdown), "No flaky examples found")
+}
+
+func TestFileReporterVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flaky.json")
+	reporter := NewFileReporter(&FileConfig{Path: path})
+
+	assert.NoError(t, reporter.Init())
+	assert.NoError(t, reporter.Verify(VerifyOptions{}))
+	assert.NoFileExists(t, path)
+
+	assert.NoError(t, reporter.Verify(VerifyOptions{CreateIssue: true}))
+	assert.FileExists(t, path)
+
+	reporter = NewFileReporter(&FileConfig{Path: filepath.Join(t.TempDir(), "missing", "flaky.json")})
+	assert.Error(t, reporter.Verify(VerifyOptions{}))
+}
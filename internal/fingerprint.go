@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Fingerprint returns a stable identifier of a flaky group, used to find
+// the issue previously created for the group regardless of its title.
+func Fingerprint(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimPrefix(key, "./")))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// FingerprintMarker is embedded (as a hidden HTML comment) in the body
+// of created Github issues
+func FingerprintMarker(fingerprint string) string {
+	return fmt.Sprintf("<!-- rspec-sanity:fingerprint=%s -->", fingerprint)
+}
+
+// FingerprintLabel is attached to created JIRA issues, labels can be
+// matched exactly with JQL
+func FingerprintLabel(fingerprint string) string {
+	return "rspec-sanity-" + fingerprint
+}
+
+// BuildKey identifies the CI build reporting the group, empty when the build
+// id can't be detected
+func BuildKey(build Build) string {
+	if build.ID == "" {
+		return ""
+	}
+
+	return build.Provider + "/" + build.ID
+}
+
+var buildMarkerRegexp = regexp.MustCompile(`<!-- rspec-sanity:build=\S+ -->`)
+
+// BuildMarker is embedded (as a hidden HTML comment) in Github issues and
+// comments, so retried jobs or parallel nodes of the same build don't report
+// the group twice
+func BuildMarker(key string) string {
+	return fmt.Sprintf("<!-- rspec-sanity:build=%s -->", key)
+}
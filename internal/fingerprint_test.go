@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := Fingerprint("./spec/flaky_spec.rb")
+
+	assert.Equal(t, 16, len(fingerprint))
+	assert.Equal(t, fingerprint, Fingerprint("spec/flaky_spec.rb"))
+	assert.NotEqual(t, fingerprint, Fingerprint("./spec/other_spec.rb"))
+
+	assert.Equal(t, "<!-- rspec-sanity:fingerprint="+fingerprint+" -->", FingerprintMarker(fingerprint))
+	assert.Equal(t, "rspec-sanity-"+fingerprint, FingerprintLabel(fingerprint))
+}
+
+func TestBuildKey(t *testing.T) {
+	assert.Equal(t, "", BuildKey(Build{Provider: ProviderCircleCI}))
+	assert.Equal(t, "circleci/job-1", BuildKey(Build{Provider: ProviderCircleCI, ID: "job-1", NodeIndex: 2}))
+	assert.Equal(t, "<!-- rspec-sanity:build=circleci/job-1 -->", BuildMarker("circleci/job-1"))
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// GithubActionsOutput appends a job summary to $GITHUB_STEP_SUMMARY and emits
+// workflow annotations for flaky examples. It is independent of the ticket
+// reporter and does nothing outside of GitHub Actions.
+type GithubActionsOutput struct {
+	Stdout      io.Writer
+	SummaryPath string
+}
+
+func NewGithubActionsOutput() *GithubActionsOutput {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return nil
+	}
+
+	return &GithubActionsOutput{
+		Stdout:      os.Stdout,
+		SummaryPath: os.Getenv("GITHUB_STEP_SUMMARY"),
+	}
+}
+
+func (o *GithubActionsOutput) Write(result *RunnerResult) error {
+	for _, example := range result.FlakyExamples {
+		fmt.Fprintln(o.Stdout, githubAnnotation("warning", example, "Flaky example (passed on re-run): "+example.Id))
+	}
+
+	if o.SummaryPath == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(o.SummaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(githubSummary(result))
+	if err != nil {
+		return err
+	}
+
+	log.Println("[github-actions] Appended job summary")
+
+	return nil
+}
+
+func githubSummary(result *RunnerResult) string {
+	var sb strings.Builder
+
+	sb.WriteString("### rspec-sanity\n\n")
+	fmt.Fprintf(&sb, "Attempts: %d, flaky examples: %d, failing examples: %d\n\n",
+		result.Attempts,
+		len(result.FlakyExamples),
+		len(result.FailedExamples),
+	)
+
+	if len(result.FlakyExamples) == 0 && len(result.FailedExamples) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("| Status | Example | File |\n")
+	sb.WriteString("| --- | --- | --- |\n")
+
+	for _, example := range result.FlakyExamples {
+		fmt.Fprintf(&sb, "| flaky | `%s` | %s |\n", markdownTableCell(example.Id), markdownTableCell(example.Path()))
+	}
+
+	for _, example := range result.FailedExamples {
+		fmt.Fprintf(&sb, "| failed | `%s` | %s |\n", markdownTableCell(example.Id), markdownTableCell(example.Path()))
+	}
+
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions
+func githubAnnotation(level string, example RspecExample, message string) string {
+	properties := []string{"file=" + escapeAnnotationProperty(example.Path())}
+
+	if line := example.Line(); line > 0 {
+		properties = append(properties, fmt.Sprintf("line=%d", line))
+	}
+
+	properties = append(properties, "title=rspec-sanity")
+
+	return fmt.Sprintf("::%s %s::%s", level, strings.Join(properties, ","), escapeAnnotationData(message))
+}
+
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+func markdownTableCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGithubActionsOutput(t *testing.T) {
+	var stdout bytes.Buffer
+	summaryPath := filepath.Join(t.TempDir(), "summary.md")
+
+	output := &GithubActionsOutput{
+		Stdout:      &stdout,
+		SummaryPath: summaryPath,
+	}
+
+	err := output.Write(&RunnerResult{
+		Attempts: 2,
+		FlakyExamples: []RspecExample{
+			{Id: "./spec/flaky_spec.rb[1:1]", Status: "failed"},
+			{Id: "./spec/other_spec.rb:12", Status: "failed"},
+		},
+		FailedExamples: []RspecExample{
+			{Id: "./spec/broken_spec.rb[1:3]", Status: "failed"},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		"::warning file=spec/flaky_spec.rb,title=rspec-sanity::Flaky example (passed on re-run): ./spec/flaky_spec.rb[1:1]\n"+
+			"::warning file=spec/other_spec.rb,line=12,title=rspec-sanity::Flaky example (passed on re-run): ./spec/other_spec.rb:12\n",
+		stdout.String(),
+	)
+
+	summary, err := os.ReadFile(summaryPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(summary), "Attempts: 2, flaky examples: 2, failing examples: 1")
+	assert.Contains(t, string(summary), "| flaky | `./spec/flaky_spec.rb[1:1]` | spec/flaky_spec.rb |")
+	assert.Contains(t, string(summary), "| failed | `./spec/broken_spec.rb[1:3]` | spec/broken_spec.rb |")
+}
+
+func TestNewGithubActionsOutput(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	assert.Nil(t, NewGithubActionsOutput())
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", "/tmp/summary")
+	assert.Equal(t, "/tmp/summary", NewGithubActionsOutput().SummaryPath)
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+const githubCheckRunName = "rspec-sanity"
+
+// GitHub accepts at most 50 annotations per check run request
+const githubCheckRunAnnotationsLimit = 50
+
+func (gr *GithubReporter) createCheckRun(result *RunnerResult) error {
+	sha := DetectHeadSHA()
+	if sha == "" {
+		log.Println("[github] Can't detect head commit, skipping check run")
+		return nil
+	}
+
+	ctx := context.Background()
+	output := githubCheckRunOutput(result)
+	annotations := output.Annotations
+	output.Annotations = nil
+
+	if len(annotations) > githubCheckRunAnnotationsLimit {
+		output.Annotations = annotations[:githubCheckRunAnnotationsLimit]
+		annotations = annotations[githubCheckRunAnnotationsLimit:]
+	} else {
+		output.Annotations = annotations
+		annotations = nil
+	}
+
+	checkRun, _, err := gr.client.Checks.CreateCheckRun(ctx, gr.config.Owner, gr.config.Repo, github.CreateCheckRunOptions{
+		Name:        githubCheckRunName,
+		HeadSHA:     sha,
+		Status:      github.String("completed"),
+		Conclusion:  github.String(githubCheckRunConclusion(result)),
+		CompletedAt: &github.Timestamp{Time: time.Now()},
+		Output:      output,
+	})
+	if err != nil {
+		return err
+	}
+
+	for len(annotations) > 0 {
+		batch := annotations
+		if len(batch) > githubCheckRunAnnotationsLimit {
+			batch = batch[:githubCheckRunAnnotationsLimit]
+		}
+		annotations = annotations[len(batch):]
+
+		_, _, err = gr.client.Checks.UpdateCheckRun(ctx, gr.config.Owner, gr.config.Repo, checkRun.GetID(), github.UpdateCheckRunOptions{
+			Name: githubCheckRunName,
+			Output: &github.CheckRunOutput{
+				Title:       output.Title,
+				Summary:     output.Summary,
+				Annotations: batch,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[github] Created check run: %s", checkRun.GetHTMLURL())
+
+	return nil
+}
+
+// success on clean runs, neutral when every failure turned out to be flaky,
+// so flakiness is visible without marking the commit as broken
+func githubCheckRunConclusion(result *RunnerResult) string {
+	if result.StatusCode != 0 || len(result.FailedExamples) > 0 {
+		return "failure"
+	}
+
+	if result.HasFlakies() {
+		return "neutral"
+	}
+
+	return "success"
+}
+
+func githubCheckRunOutput(result *RunnerResult) *github.CheckRunOutput {
+	summary := fmt.Sprintf(
+		"Attempts: %d\n\nFlaky examples (failed on the first attempt, passed on re-run): %d\n\nFailing examples (failed on every attempt): %d",
+		result.Attempts,
+		len(result.FlakyExamples),
+		len(result.FailedExamples),
+	)
+
+	var annotations []*github.CheckRunAnnotation
+
+	for _, example := range result.FlakyExamples {
+		line := example.Line()
+		if line == 0 {
+			line = 1
+		}
+
+		annotations = append(annotations, &github.CheckRunAnnotation{
+			Path:            github.String(example.Path()),
+			StartLine:       github.Int(line),
+			EndLine:         github.Int(line),
+			AnnotationLevel: github.String("warning"),
+			Title:           github.String("Flaky example"),
+			Message:         github.String(fmt.Sprintf("%s failed on the first attempt and passed on re-run", example.Id)),
+		})
+	}
+
+	title := fmt.Sprintf("%d flaky example(s) detected", len(result.FlakyExamples))
+	if !result.HasFlakies() {
+		title = "No flaky examples detected"
+	}
+
+	return &github.CheckRunOutput{
+		Title:       github.String(title),
+		Summary:     github.String(summary),
+		Annotations: annotations,
+	}
+}
@@ -3,18 +3,29 @@ package internal
 import (
 	"fmt"
 	"os"
+	"time"
 )
 
 type GithubConfig struct {
-	Owner string `toml:"owner,omitempty"`
-	Repo string `toml:"repo,omitempty"`
-	Template string `toml:"template,omitempty"`
-	Labels []string `toml:"labels,omitempty"`
-	Reopen bool `toml:"reopen,omitempty"`
-	token string
+	Owner              string   `toml:"owner,omitempty"`
+	Repo               string   `toml:"repo,omitempty"`
+	Template           string   `toml:"template,omitempty"`
+	TemplateFile       string   `toml:"template_file,omitempty"`
+	TitleTemplate      string   `toml:"title_template,omitempty"`
+	Labels             []string `toml:"labels,omitempty"`
+	Reopen             bool     `toml:"reopen,omitempty"`
+	PullRequestComment bool     `toml:"pr_comment,omitempty"`
+	CheckRun           bool     `toml:"check_run,omitempty"`
+	Summary            bool     `toml:"summary,omitempty"`
+	Comments           string   `toml:"comments,omitempty"`
+	MinCommentInterval string   `toml:"min_comment_interval,omitempty"`
+	minCommentInterval time.Duration
+	token              string
+	template           *Template
+	titleTemplate      *Template
 }
 
-func (gc *GithubConfig) Prepare() error {
+func (gc *GithubConfig) Prepare(opts TemplateOptions) error {
 	if gc.Owner == "" {
 		return fmt.Errorf("no github owner specified in config")
 	}
@@ -23,10 +34,38 @@ func (gc *GithubConfig) Prepare() error {
 		return fmt.Errorf("no github repo specified in config")
 	}
 
-	if gc.Template == "" {
+	if gc.Template == "" && gc.TemplateFile == "" {
 		return fmt.Errorf("no github template specified in config")
 	}
 
+	err := validateCommentsMode("github", gc.Comments)
+	if err != nil {
+		return err
+	}
+
+	gc.minCommentInterval, err = parseCommentInterval("github", gc.MinCommentInterval)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := LoadTemplate("github.template", gc.Template, gc.TemplateFile, opts)
+	if err != nil {
+		return err
+	}
+	gc.template = tmpl
+
+	if gc.TitleTemplate != "" {
+		tmpl, err = LoadTemplate("github.title_template", gc.TitleTemplate, "", opts)
+		if err != nil {
+			return err
+		}
+		gc.titleTemplate = tmpl
+	}
+
+	return nil
+}
+
+func (gc *GithubConfig) LoadCredentials() error {
 	token, present := os.LookupEnv("RSPEC_SANITY_GITHUB_TOKEN")
 
 	if !present {
@@ -40,3 +79,15 @@ func (gc *GithubConfig) Prepare() error {
 func (gc *GithubConfig) GetToken() string {
 	return gc.token
 }
+
+func (gc *GithubConfig) GetMinCommentInterval() time.Duration {
+	return gc.minCommentInterval
+}
+
+func (gc *GithubConfig) GetTemplate() *Template {
+	return gc.template
+}
+
+func (gc *GithubConfig) GetTitleTemplate() *Template {
+	return gc.titleTemplate
+}
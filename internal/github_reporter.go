@@ -2,8 +2,14 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v50/github"
 	"golang.org/x/exp/slices"
@@ -11,10 +17,25 @@ import (
 )
 
 type GithubReporter struct {
-	config *GithubConfig
-	client *github.Client
+	config   *GithubConfig
+	client   *github.Client
+	mu       sync.Mutex
+	reported []githubReportedGroup
+	// deadline of all API calls, counted from Init (see report_timeout)
+	reportTimeout time.Duration
 }
 
+type githubReportedGroup struct {
+	Group FlakyGroup
+	Issue *github.Issue
+}
+
+const githubPullRequestCommentMarker = "<!-- rspec-sanity:pr-comment -->"
+
+// githubReportCommentMarker tells recurrence comments apart from replies of
+// people
+const githubReportCommentMarker = "<!-- rspec-sanity:report -->"
+
 func NewGithubReporter(gc *GithubConfig) *GithubReporter {
 	return &GithubReporter{
 		config: gc,
@@ -22,7 +43,9 @@ func NewGithubReporter(gc *GithubConfig) *GithubReporter {
 }
 
 func (gr *GithubReporter) Init() error {
-	ctx := context.Background()
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+		Transport: NewRetryTransport(gr.reportTimeout),
+	})
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: gr.config.GetToken()},
 	)
@@ -32,74 +55,397 @@ func (gr *GithubReporter) Init() error {
 	return nil
 }
 
-func (gr *GithubReporter) Verify() error {
+func (gr *GithubReporter) Verify(opts VerifyOptions) error {
 	log.Println("[github] Verifying reporter")
 
-	testFlakies := []RspecExample{
-		{Id: "some/test-example.rb:1:2"},
-		{Id: "some/test-example.rb:10:2"},
+	ctx := context.Background()
+	checklist := NewChecklist("github")
+
+	checklist.Check("template", func() (string, error) {
+		_, err := ExecuteTemplate(gr.config.GetTemplate(), verificationGroup)
+		return "", err
+	})
+
+	// GET /user is forbidden for Actions and App installation tokens, rate
+	// limits are readable with any valid token
+	checklist.Check("credentials", func() (string, error) {
+		limits, _, err := gr.client.RateLimits(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d/%d API requests remaining", limits.GetCore().Remaining, limits.GetCore().Limit), nil
+	})
+
+	checklist.Check("repository access", func() (string, error) {
+		repo, _, err := gr.client.Repositories.Get(ctx, gr.config.Owner, gr.config.Repo)
+		if err != nil {
+			return "", err
+		}
+
+		if !repo.GetHasIssues() {
+			return "", fmt.Errorf("issues are disabled in %s", repo.GetFullName())
+		}
+
+		permissions := repo.GetPermissions()
+		if len(permissions) > 0 && !permissions["triage"] && !permissions["push"] && !permissions["maintain"] && !permissions["admin"] {
+			return "", fmt.Errorf("no write access to issues in %s", repo.GetFullName())
+		}
+
+		return repo.GetFullName(), nil
+	})
+
+	for _, label := range gr.config.Labels {
+		checklist.Check(fmt.Sprintf(`label "%s"`, label), func() (string, error) {
+			_, _, err := gr.client.Issues.GetLabel(ctx, gr.config.Owner, gr.config.Repo, label)
+			return "", err
+		})
 	}
-	template, err := RenderTemplate(gr.config.Template, testFlakies)
-	if err != nil {
-		return err
+
+	checklist.Check("issue search", func() (string, error) {
+		query := fmt.Sprintf("repo:%s/%s is:issue", gr.config.Owner, gr.config.Repo)
+		_, _, err := gr.client.Search.Issues(ctx, query, &github.SearchOptions{
+			ListOptions: github.ListOptions{PerPage: 1},
+		})
+		return "", err
+	})
+
+	if opts.CreateIssue {
+		checklist.Check("test issue", gr.createTestIssue)
 	}
 
-	issue, err := gr._createIssue("Test Issue", template, gr.config.Labels)
+	return checklist.Err()
+}
 
+// createTestIssue creates a test issue and closes it right away - issues
+// can't be deleted with the REST API
+func (gr *GithubReporter) createTestIssue() (string, error) {
+	template, err := ExecuteTemplate(gr.config.GetTemplate(), verificationGroup)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	log.Printf("[github] Created test issue: %s", *issue.HTMLURL)
-	
-	return nil
-}
+	issue, err := gr._createIssue("Test Issue", template, gr.config.Labels)
+	if err != nil {
+		return "", err
+	}
 
-func (gr *GithubReporter) ReportFlaky(flakies []RspecExample) error {
-	issueTitle := flakies[0].Filename()
-	query := fmt.Sprintf("\"%s\" in:title repo:%s/%s is:issue",
-		issueTitle,
+	_, _, err = gr.client.Issues.Edit(
+		context.Background(),
 		gr.config.Owner,
 		gr.config.Repo,
+		issue.GetNumber(),
+		&github.IssueRequest{
+			State:       github.String("closed"),
+			StateReason: github.String("not_planned"),
+		},
 	)
+	if err != nil {
+		return "", fmt.Errorf("created %s but failed to close it: %w", issue.GetHTMLURL(), err)
+	}
 
-	results, _, err := gr.client.Search.Issues(context.Background(), query, &github.SearchOptions{
-		ListOptions: github.ListOptions{
-			Page:    0,
-			PerPage: 10,
-		},
-	})
+	return "created and closed " + issue.GetHTMLURL(), nil
+}
 
+func (gr *GithubReporter) ReportFlaky(group FlakyGroup) error {
+	issue, err := gr.findIssue(group.Key, Fingerprint(group.Key))
 	if err != nil {
 		return err
 	}
 
-	if *results.Total == 0 {
+	if issue == nil {
 		log.Println("[github] No issues found, creating new one")
-		return gr.createIssue(flakies)
-	} else {
+		return gr.createIssue(group)
+	}
+
+	log.Printf("[github] Updating issue %s", *issue.Title)
+
+	err = gr.updateIssue(issue, group)
+	if err != nil {
+		return err
+	}
+
+	gr.track(group, issue)
+	return nil
+}
+
+// findIssue looks up the issue by the fingerprint marker embedded in its body;
+// issues created before fingerprints were introduced are matched by the exact
+// title (which used to always be the group key). Returns nil when there is no
+// match so a new issue gets created.
+func (gr *GithubReporter) findIssue(title string, fingerprint string) (*github.Issue, error) {
+	marker := FingerprintMarker(fingerprint)
+
+	for _, query := range []string{
+		fmt.Sprintf("\"%s\" in:body repo:%s/%s is:issue", fingerprint, gr.config.Owner, gr.config.Repo),
+		fmt.Sprintf("\"%s\" in:title repo:%s/%s is:issue", title, gr.config.Owner, gr.config.Repo),
+	} {
+		results, _, err := gr.client.Search.Issues(context.Background(), query, &github.SearchOptions{
+			ListOptions: github.ListOptions{
+				Page:    0,
+				PerPage: 10,
+			},
+		})
+
+		if err != nil {
+			return nil, err
+		}
 
 		idx := slices.IndexFunc(results.Issues, func(c *github.Issue) bool {
-			return *c.Title == flakies[0].Filename()
+			return strings.Contains(c.GetBody(), marker)
 		})
 
 		if idx == -1 {
-			log.Println("[github] Can't find exact match, doing fallback")
-			idx = 0
+			idx = slices.IndexFunc(results.Issues, func(c *github.Issue) bool {
+				return c.GetTitle() == title
+			})
+		}
+
+		if idx != -1 {
+			return results.Issues[idx], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (gr *GithubReporter) track(group FlakyGroup, issue *github.Issue) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	gr.reported = append(gr.reported, githubReportedGroup{
+		Group: group,
+		Issue: issue,
+	})
+
+	// groups can be reported concurrently
+	sort.SliceStable(gr.reported, func(i, j int) bool {
+		return gr.reported[i].Group.Key < gr.reported[j].Group.Key
+	})
+}
+
+func (gr *GithubReporter) Finalize(result *RunnerResult) error {
+	if gr.config.CheckRun {
+		err := gr.createCheckRun(result)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !gr.config.PullRequestComment {
+		return nil
+	}
+
+	number := DetectPullRequestNumber()
+	if number == 0 {
+		log.Println("[github] Can't detect pull request number, skipping pull request comment")
+		return nil
+	}
+
+	body, flaky := gr.pullRequestCommentBody()
+
+	// on clean builds only a comment left by an earlier build is updated
+	return gr.upsertPullRequestComment(number, body, flaky)
+}
+
+// pullRequestCommentBody renders the comment, tells whether any flaky
+// example was reported
+func (gr *GithubReporter) pullRequestCommentBody() (string, bool) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	var sb strings.Builder
+
+	sb.WriteString(githubPullRequestCommentMarker + "\n")
+
+	if len(gr.reported) == 0 {
+		sb.WriteString("### rspec-sanity: no flaky examples detected in this build\n")
+		return sb.String(), false
+	}
+
+	sb.WriteString("### rspec-sanity: flaky examples detected in this build\n\n")
+	sb.WriteString("| Example | Tracking issue |\n")
+	sb.WriteString("| --- | --- |\n")
+
+	for _, group := range gr.reported {
+		for _, example := range group.Group.Examples {
+			fmt.Fprintf(&sb, "| `%s` | [#%d](%s) |\n",
+				markdownTableCell(example.Id),
+				group.Issue.GetNumber(),
+				group.Issue.GetHTMLURL(),
+			)
+		}
+	}
+
+	return sb.String(), true
+}
+
+// upsertPullRequestComment keeps a single "sticky" comment on the pull request,
+// found by the hidden marker, and updates it in place on every build; a new
+// comment is added only when create is set
+func (gr *GithubReporter) upsertPullRequestComment(number int, body string, create bool) error {
+	ctx := context.Background()
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		comments, resp, err := gr.client.Issues.ListComments(ctx, gr.config.Owner, gr.config.Repo, number, opts)
+		if err != nil {
+			return err
+		}
+
+		for _, comment := range comments {
+			if strings.Contains(comment.GetBody(), githubPullRequestCommentMarker) {
+				_, _, err = gr.client.Issues.EditComment(ctx, gr.config.Owner, gr.config.Repo, comment.GetID(), &github.IssueComment{
+					Body: github.String(body),
+				})
+				if err != nil {
+					return err
+				}
+
+				log.Printf("[github] Updated pull request #%d comment", number)
+				return nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
 		}
+		opts.Page = resp.NextPage
+	}
 
-		log.Printf("[github] Adding comment to issue %s", *(results.Issues[idx]).Title)
+	if !create {
+		return nil
+	}
 
-		return gr.addIssueComment(results.Issues[idx], flakies)
+	_, _, err := gr.client.Issues.CreateComment(ctx, gr.config.Owner, gr.config.Repo, number, &github.IssueComment{
+		Body: github.String(body),
+	})
+	if err != nil {
+		return err
 	}
+
+	log.Printf("[github] Added pull request #%d comment", number)
+
+	return nil
 }
 
-func (gr *GithubReporter) addIssueComment(issue *github.Issue, flakies []RspecExample) error {
-	body, err := RenderTemplate(gr.config.Template, flakies)
+// updateIssue records the recurrence: updates the summary, adds a comment
+// (unless limited by the comments setting) and reopens the issue
+func (gr *GithubReporter) updateIssue(issue *github.Issue, group FlakyGroup) error {
+	comments, err := gr.listComments(issue)
 	if err != nil {
 		return err
 	}
 
+	build := BuildKey(group.DetectedBuild())
+	if build != "" && githubReportedInBuild(issue, comments, build) {
+		log.Printf("[github] Issue #%d was already updated by build %s, skipping", issue.GetNumber(), build)
+		return nil
+	}
+
+	if gr.config.Summary {
+		err = gr.updateSummary(issue, group)
+		if err != nil {
+			return err
+		}
+	}
+
+	if shouldComment(gr.config.Comments, gr.config.GetMinCommentInterval(), githubLastComment(issue, comments), time.Now()) {
+		err = gr.addIssueComment(issue, group)
+	} else {
+		log.Printf("[github] Skipping comment on issue #%d (comments = %s, min_comment_interval = %s)", issue.GetNumber(), gr.config.Comments, gr.config.MinCommentInterval)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return gr.reopenIssue(issue)
+}
+
+// githubReportedInBuild looks for the build marker in the issue body, its
+// summary and comments
+func githubReportedInBuild(issue *github.Issue, comments []*github.IssueComment, build string) bool {
+	marker := BuildMarker(build)
+
+	if strings.Contains(issue.GetBody(), marker) || ParseMarkdownSummary(issue.GetBody()).LastBuild == build {
+		return true
+	}
+
+	return slices.ContainsFunc(comments, func(c *github.IssueComment) bool {
+		return strings.Contains(c.GetBody(), marker)
+	})
+}
+
+// githubLastComment is the time of the latest recurrence comment (replies of
+// people don't count) or of the issue creation
+func githubLastComment(issue *github.Issue, comments []*github.IssueComment) time.Time {
+	for i := len(comments) - 1; i >= 0; i-- {
+		body := comments[i].GetBody()
+
+		// comments added before the report marker carry only the build marker
+		if strings.Contains(body, githubReportCommentMarker) || buildMarkerRegexp.MatchString(body) {
+			return comments[i].GetCreatedAt().Time
+		}
+	}
+
+	return issue.GetCreatedAt().Time
+}
+
+// listComments returns all comments of the issue, oldest first (the endpoint
+// doesn't support sorting)
+func (gr *GithubReporter) listComments(issue *github.Issue) ([]*github.IssueComment, error) {
+	var comments []*github.IssueComment
+
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		page, resp, err := gr.client.Issues.ListComments(context.Background(), gr.config.Owner, gr.config.Repo, issue.GetNumber(), opts)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, page...)
+
+		if resp.NextPage == 0 {
+			return comments, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (gr *GithubReporter) updateSummary(issue *github.Issue, group FlakyGroup) error {
+	summary := ParseMarkdownSummary(issue.GetBody())
+	summary.Record(group, group.DetectedBuild(), time.Now())
+
+	_, _, err := gr.client.Issues.Edit(
+		context.Background(),
+		gr.config.Owner,
+		gr.config.Repo,
+		issue.GetNumber(),
+		&github.IssueRequest{
+			Body: github.String(ReplaceMarkdownSummary(issue.GetBody(), &summary)),
+		},
+	)
+
+	return err
+}
+
+func (gr *GithubReporter) addIssueComment(issue *github.Issue, group FlakyGroup) error {
+	body, err := ExecuteTemplate(gr.config.GetTemplate(), group)
+	if err != nil {
+		return err
+	}
+
+	body = body + "\n\n" + githubReportCommentMarker
+
+	if build := BuildKey(group.DetectedBuild()); build != "" {
+		body = body + "\n" + BuildMarker(build)
+	}
+
 	comment := &github.IssueComment{
 		Body: github.String(body),
 	}
@@ -112,12 +458,12 @@ func (gr *GithubReporter) addIssueComment(issue *github.Issue, flakies []RspecEx
 		comment,
 	)
 
-	if err != nil {
-		return err
-	}
+	return err
+}
 
+func (gr *GithubReporter) reopenIssue(issue *github.Issue) error {
 	if gr.config.Reopen && *issue.State == *github.String("closed") {
-		_, _, err = gr.client.Issues.Edit(
+		_, _, err := gr.client.Issues.Edit(
 			context.Background(),
 			gr.config.Owner,
 			gr.config.Repo,
@@ -127,21 +473,36 @@ func (gr *GithubReporter) addIssueComment(issue *github.Issue, flakies []RspecEx
 			},
 		)
 
-		if err != nil {
-			return err
-		}
+		return err
 	}
 
 	return nil
 }
 
-func (gr *GithubReporter) createIssue(flakies []RspecExample) error {
-	body, err := RenderTemplate(gr.config.Template, flakies)
+func (gr *GithubReporter) createIssue(group FlakyGroup) error {
+	body, err := ExecuteTemplate(gr.config.GetTemplate(), group)
 	if err != nil {
 		return err
 	}
 
-	issue, err := gr._createIssue(flakies[0].Filename(), body, gr.config.Labels)
+	title, err := RenderTitle(gr.config.GetTitleTemplate(), group)
+	if err != nil {
+		return err
+	}
+
+	body = body + "\n\n" + FingerprintMarker(Fingerprint(group.Key))
+
+	if build := BuildKey(group.DetectedBuild()); build != "" {
+		body = body + "\n" + BuildMarker(build)
+	}
+
+	if gr.config.Summary {
+		summary := OccurrenceSummary{}
+		summary.Record(group, group.DetectedBuild(), time.Now())
+		body = ReplaceMarkdownSummary(body, &summary)
+	}
+
+	issue, err := gr._createIssue(title, body, gr.config.Labels)
 
 	if err != nil {
 		return err
@@ -149,6 +510,7 @@ func (gr *GithubReporter) createIssue(flakies []RspecExample) error {
 
 	log.Printf("[github] Created new issue: %s", *issue.Title)
 
+	gr.track(group, issue)
 	return nil
 }
 
@@ -172,3 +534,113 @@ func (gr *GithubReporter) _createIssue(title string, body string, labels []strin
 
 	return newIssue, err
 }
+
+func (gr *GithubReporter) CloseStale(opts StaleOptions) error {
+	ctx := context.Background()
+	listOpts := &github.IssueListByRepoOptions{
+		State:       "open",
+		Labels:      gr.config.Labels,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	// closed issues drop out of the listing and would shift the following
+	// pages, so issues are closed once every page is fetched
+	var candidates []*github.Issue
+
+	for {
+		issues, resp, err := gr.client.Issues.ListByRepo(ctx, gr.config.Owner, gr.config.Repo, listOpts)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			if !issue.IsPullRequest() && fingerprintMarkerRegexp.MatchString(issue.GetBody()) {
+				candidates = append(candidates, issue)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+
+	fingerprints := make([]string, len(candidates))
+	for i, issue := range candidates {
+		fingerprints[i] = fingerprintMarkerRegexp.FindStringSubmatch(issue.GetBody())[1]
+	}
+	opts.MatchSpecFiles(fingerprints)
+
+	var errs []error
+
+	for i, issue := range candidates {
+		lastSeen, err := gr.lastOccurrence(issue)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		fingerprint := fingerprints[i]
+		reason := opts.StaleReason(fingerprint, lastSeen)
+		if reason == "" {
+			continue
+		}
+
+		if opts.DryRun {
+			log.Printf("[github] Would close issue #%d %s: %s", issue.GetNumber(), issue.GetTitle(), reason)
+			continue
+		}
+
+		err = gr.closeIssue(issue, staleComment(reason))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		log.Printf("[github] Closed issue #%d %s: %s", issue.GetNumber(), issue.GetTitle(), reason)
+	}
+
+	return errors.Join(errs...)
+}
+
+// lastOccurrence reads the occurrence summary - recurrences aren't always
+// commented on (see comments setting) - falling back to the latest comment
+// for issues without one
+func (gr *GithubReporter) lastOccurrence(issue *github.Issue) (time.Time, error) {
+	if summary := ParseMarkdownSummary(issue.GetBody()); summary.Count > 0 {
+		return summary.LastSeen, nil
+	}
+
+	comments, err := gr.listComments(issue)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return githubLastComment(issue, comments), nil
+}
+
+func (gr *GithubReporter) closeIssue(issue *github.Issue, comment string) error {
+	_, _, err := gr.client.Issues.CreateComment(
+		context.Background(),
+		gr.config.Owner,
+		gr.config.Repo,
+		issue.GetNumber(),
+		&github.IssueComment{Body: github.String(comment)},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = gr.client.Issues.Edit(
+		context.Background(),
+		gr.config.Owner,
+		gr.config.Repo,
+		issue.GetNumber(),
+		&github.IssueRequest{
+			State:       github.String("closed"),
+			StateReason: github.String("completed"),
+		},
+	)
+
+	return err
+}
@@ -0,0 +1,204 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGithubPullRequestCommentBody(t *testing.T) {
+	reporter := NewGithubReporter(&GithubConfig{})
+	reporter.track(
+		FlakyGroup{Key: "./spec/flaky_spec.rb", Examples: []RspecExample{{Id: "./spec/flaky_spec.rb[1:1]"}}},
+		&github.Issue{Number: github.Int(12), HTMLURL: github.String("https://github.com/jdoe/repo/issues/12")},
+	)
+
+	body, flaky := reporter.pullRequestCommentBody()
+
+	assert.True(t, flaky)
+	assert.Contains(t, body, githubPullRequestCommentMarker)
+	assert.Contains(t, body, "| `./spec/flaky_spec.rb[1:1]` | [#12](https://github.com/jdoe/repo/issues/12) |")
+}
+
+// newTestGithubReporter points the reporter's client to a test server
+func newTestGithubReporter(t *testing.T, config *GithubConfig, handler http.Handler) *GithubReporter {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	reporter := NewGithubReporter(config)
+	reporter.client = github.NewClient(nil)
+	reporter.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	return reporter
+}
+
+func TestGithubPullRequestCommentWithoutFlakies(t *testing.T) {
+	clearBuildEnv(t)
+	t.Setenv("RSPEC_SANITY_PR_NUMBER", "7")
+
+	var edited string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/jdoe/repo/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*github.IssueComment{
+			{ID: github.Int64(1), Body: github.String("LGTM")},
+			{ID: github.Int64(2), Body: github.String(githubPullRequestCommentMarker + "\n| `./spec/flaky_spec.rb[1:1]` |")},
+		})
+	})
+	mux.HandleFunc("PATCH /repos/jdoe/repo/issues/comments/2", func(w http.ResponseWriter, r *http.Request) {
+		var comment github.IssueComment
+		json.NewDecoder(r.Body).Decode(&comment)
+		edited = comment.GetBody()
+		json.NewEncoder(w).Encode(comment)
+	})
+
+	reporter := newTestGithubReporter(t, &GithubConfig{Owner: "jdoe", Repo: "repo", PullRequestComment: true}, mux)
+
+	err := reporter.Finalize(&RunnerResult{Attempts: 1})
+	assert.NoError(t, err)
+	assert.Contains(t, edited, githubPullRequestCommentMarker)
+	assert.Contains(t, edited, "no flaky examples detected in this build")
+	assert.NotContains(t, edited, "flaky_spec.rb")
+}
+
+func TestGithubCheckRunOutput(t *testing.T) {
+	result := &RunnerResult{
+		Attempts: 2,
+		FlakyExamples: []RspecExample{
+			{Id: "./spec/flaky_spec.rb[1:1]"},
+			{Id: "./spec/other_spec.rb:12"},
+		},
+	}
+
+	assert.Equal(t, "neutral", githubCheckRunConclusion(result))
+
+	output := githubCheckRunOutput(result)
+	assert.Equal(t, "2 flaky example(s) detected", output.GetTitle())
+	assert.Equal(t, 2, len(output.Annotations))
+	assert.Equal(t, "spec/flaky_spec.rb", output.Annotations[0].GetPath())
+	assert.Equal(t, 1, output.Annotations[0].GetStartLine())
+	assert.Equal(t, 12, output.Annotations[1].GetStartLine())
+
+	result.FailedExamples = []RspecExample{{Id: "./spec/broken_spec.rb[1:1]"}}
+	result.StatusCode = 1
+	assert.Equal(t, "failure", githubCheckRunConclusion(result))
+
+	// real failures only
+	result.FlakyExamples = nil
+	assert.Equal(t, "failure", githubCheckRunConclusion(result))
+
+	clean := &RunnerResult{Attempts: 1}
+	assert.Equal(t, "success", githubCheckRunConclusion(clean))
+	assert.Equal(t, "No flaky examples detected", githubCheckRunOutput(clean).GetTitle())
+}
+
+func TestGithubCheckRunOnPullRequestHead(t *testing.T) {
+	clearBuildEnv(t)
+	t.Setenv("GITHUB_SHA", "merge-sha")
+
+	event := filepath.Join(t.TempDir(), "event.json")
+	assert.NoError(t, os.WriteFile(event, []byte(`{"pull_request": {"head": {"sha": "head-sha"}}}`), 0644))
+	t.Setenv("GITHUB_EVENT_PATH", event)
+
+	var created github.CreateCheckRunOptions
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/jdoe/repo/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&created)
+		json.NewEncoder(w).Encode(&github.CheckRun{ID: github.Int64(1)})
+	})
+
+	reporter := newTestGithubReporter(t, &GithubConfig{Owner: "jdoe", Repo: "repo", CheckRun: true}, mux)
+
+	err := reporter.Finalize(&RunnerResult{Attempts: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "head-sha", created.HeadSHA)
+	assert.Equal(t, "success", created.GetConclusion())
+}
+
+func TestGithubReporterVerify(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		// as for GITHUB_TOKEN of GitHub Actions
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	})
+	mux.HandleFunc("GET /rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"resources": {"core": {"limit": 1000, "remaining": 999}}}`))
+	})
+	mux.HandleFunc("GET /repos/jdoe/repo", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&github.Repository{FullName: github.String("jdoe/repo"), HasIssues: github.Bool(true)})
+	})
+	mux.HandleFunc("GET /search/issues", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&github.IssuesSearchResult{})
+	})
+
+	config := &GithubConfig{Owner: "jdoe", Repo: "repo", Template: "{{ .GroupKey }}"}
+	assert.NoError(t, config.Prepare(TemplateOptions{}))
+	reporter := newTestGithubReporter(t, config, mux)
+
+	assert.NoError(t, reporter.Verify(VerifyOptions{}))
+}
+
+func TestGithubReportedInBuild(t *testing.T) {
+	created := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	issue := &github.Issue{Body: github.String("report"), CreatedAt: &github.Timestamp{Time: created}}
+	comments := []*github.IssueComment{
+		{Body: github.String("report\n\n" + BuildMarker("circleci/job-1")), CreatedAt: &github.Timestamp{Time: created.Add(time.Hour)}},
+		{Body: github.String("report"), CreatedAt: &github.Timestamp{Time: created.Add(2 * time.Hour)}},
+	}
+
+	assert.True(t, githubReportedInBuild(issue, comments, "circleci/job-1"))
+	assert.False(t, githubReportedInBuild(issue, comments, "circleci/job-2"))
+
+	issue.Body = github.String("report\n" + BuildMarker("circleci/job-2"))
+	assert.True(t, githubReportedInBuild(issue, nil, "circleci/job-2"))
+
+	// replies of people don't count
+	assert.Equal(t, created.Add(time.Hour), githubLastComment(issue, comments))
+	assert.Equal(t, created, githubLastComment(issue, nil))
+
+	comments = append(comments, &github.IssueComment{
+		Body:      github.String("report\n\n" + githubReportCommentMarker),
+		CreatedAt: &github.Timestamp{Time: created.Add(3 * time.Hour)},
+	})
+	assert.Equal(t, created.Add(3*time.Hour), githubLastComment(issue, comments))
+}
+
+func TestGithubLastOccurrence(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	created := &github.Timestamp{Time: now.Add(-90 * 24 * time.Hour)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/jdoe/repo/issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*github.IssueComment{
+			{Body: github.String("report\n\n" + githubReportCommentMarker), CreatedAt: &github.Timestamp{Time: now.Add(-60 * 24 * time.Hour)}},
+		})
+	})
+
+	reporter := newTestGithubReporter(t, &GithubConfig{Owner: "jdoe", Repo: "repo"}, mux)
+
+	// recurrences no longer commented on (see comments setting)
+	summary := OccurrenceSummary{Count: 5, FirstSeen: created.Time, LastSeen: now.Add(-24 * time.Hour)}
+	issue := &github.Issue{
+		Number:    github.Int(1),
+		Body:      github.String(ReplaceMarkdownSummary("report", &summary)),
+		CreatedAt: created,
+	}
+
+	lastSeen, err := reporter.lastOccurrence(issue)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), lastSeen)
+
+	// issues without summary
+	issue.Body = github.String("report")
+	lastSeen, err = reporter.lastOccurrence(issue)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-60*24*time.Hour), lastSeen)
+}
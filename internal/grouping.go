@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	GroupByFile      = "file"
+	GroupByExample   = "example"
+	GroupByDirectory = "directory"
+	GroupByOwner     = "owner"
+)
+
+// FlakyGroup is a set of flaky examples reported as a single ticket; Key is
+// the human readable group identifier (and the base of its fingerprint).
+type FlakyGroup struct {
+	Key      string
+	Examples []RspecExample
+	// Build the examples were detected in, nil for the current one (set when
+	// replaying spooled reports)
+	Build *Build
+}
+
+// DetectedBuild returns the build the group was detected in
+func (g *FlakyGroup) DetectedBuild() Build {
+	if g.Build != nil {
+		return *g.Build
+	}
+
+	return DetectBuild()
+}
+
+// verificationGroup is reported by `verify` command
+var verificationGroup = FlakyGroup{
+	Key: "some/test-example.rb",
+	Examples: []RspecExample{
+		{Id: "some/test-example.rb:1:2"},
+		{Id: "some/test-example.rb:10:2"},
+	},
+}
+
+func GroupFlakies(flakies []RspecExample, groupBy string, owners *CodeOwners) ([]FlakyGroup, error) {
+	var groups []FlakyGroup
+	index := make(map[string]int)
+
+	for _, example := range flakies {
+		key, err := groupKey(example, groupBy, owners)
+		if err != nil {
+			return nil, err
+		}
+
+		idx, ok := index[key]
+		if !ok {
+			idx = len(groups)
+			index[key] = idx
+			groups = append(groups, FlakyGroup{Key: key})
+		}
+
+		groups[idx].Examples = append(groups[idx].Examples, example)
+	}
+
+	return groups, nil
+}
+
+func groupKey(example RspecExample, groupBy string, owners *CodeOwners) (string, error) {
+	switch groupBy {
+	case "", GroupByFile:
+		return example.Filename(), nil
+	case GroupByExample:
+		return example.Id, nil
+	case GroupByDirectory:
+		return path.Dir(example.Filename()), nil
+	case GroupByOwner:
+		found := owners.Owners(example.Path())
+		if len(found) == 0 {
+			return "unowned", nil
+		}
+		return strings.Join(found, " "), nil
+	default:
+		return "", fmt.Errorf(`unknown group_by value: "%s"`, groupBy)
+	}
+}
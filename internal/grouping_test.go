@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupFlakies(t *testing.T) {
+	flakies := []RspecExample{
+		{Id: "./spec/models/user_spec.rb[1:1]"},
+		{Id: "./spec/lib/parser_spec.rb[1:1]"},
+		{Id: "./spec/models/user_spec.rb[1:2]"},
+		{Id: "./spec/models/account_spec.rb[2:1]"},
+	}
+
+	groups, err := GroupFlakies(flakies, GroupByFile, &CodeOwners{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(groups))
+	assert.Equal(t, "./spec/models/user_spec.rb", groups[0].Key)
+	assert.Equal(t, 2, len(groups[0].Examples))
+
+	groups, err = GroupFlakies(flakies, GroupByExample, &CodeOwners{})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(groups))
+	assert.Equal(t, "./spec/models/user_spec.rb[1:2]", groups[2].Key)
+
+	groups, err = GroupFlakies(flakies, GroupByDirectory, &CodeOwners{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(groups))
+	assert.Equal(t, "spec/models", groups[0].Key)
+	assert.Equal(t, 3, len(groups[0].Examples))
+
+	owners, err := ParseCodeOwners(strings.NewReader("/spec/models/ @org/backend"))
+	assert.NoError(t, err)
+
+	groups, err = GroupFlakies(flakies, GroupByOwner, owners)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(groups))
+	assert.Equal(t, "@org/backend", groups[0].Key)
+	assert.Equal(t, "unowned", groups[1].Key)
+
+	_, err = GroupFlakies(flakies, "team", &CodeOwners{})
+	assert.Error(t, err)
+}
@@ -3,17 +3,54 @@ package internal
 import (
 	"fmt"
 	"os"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	JiraEditionCloud  = "cloud"
+	JiraEditionServer = "server"
+)
+
+const (
+	JiraFormatWiki = "wiki"
+	JiraFormatADF  = "adf"
 )
 
 type JiraConfig struct {
-	EpicId string `toml:"epic_id,omitempty"`
-	ProjectId string `toml:"project_id,omitempty"`
-	TaskTypeId string `toml:"task_type_id,omitempty"`
-	Template string `toml:"template,omitempty"`
-	Labels []string `toml:"labels,omitempty"`
-	token string
-	user string
-	host string
+	Edition              string   `toml:"edition,omitempty"`
+	Format               string   `toml:"format,omitempty"`
+	EpicId               string   `toml:"epic_id,omitempty"`
+	EpicLinkField        string   `toml:"epic_link_field,omitempty"`
+	ParentId             string   `toml:"parent_id,omitempty"`
+	JqlFilter            string   `toml:"jql_filter,omitempty"`
+	ScopeLabel           string   `toml:"scope_label,omitempty"`
+	ProjectId            string   `toml:"project_id,omitempty"`
+	TaskTypeId           string   `toml:"task_type_id,omitempty"`
+	Template             string   `toml:"template,omitempty"`
+	TemplateFile         string   `toml:"template_file,omitempty"`
+	TitleTemplate        string   `toml:"title_template,omitempty"`
+	Labels               []string `toml:"labels,omitempty"`
+	CloseTransition      string   `toml:"close_transition,omitempty"`
+	ReopenTransition     string   `toml:"reopen_transition,omitempty"`
+	ReopenUnassign       bool     `toml:"reopen_unassign,omitempty"`
+	Summary              bool     `toml:"summary,omitempty"`
+	Comments             string   `toml:"comments,omitempty"`
+	MinCommentInterval   string   `toml:"min_comment_interval,omitempty"`
+	minCommentInterval   time.Duration
+	Components           []string       `toml:"components,omitempty"`
+	Priority             string         `toml:"priority,omitempty"`
+	FixVersions          []string       `toml:"fix_versions,omitempty"`
+	AssigneeId           string         `toml:"assignee_id,omitempty"`
+	ReporterId           string         `toml:"reporter_id,omitempty"`
+	CustomFields         map[string]any `toml:"custom_fields,omitempty"`
+	customFieldTemplates map[string]*Template
+	token                string
+	template             *Template
+	titleTemplate        *Template
+	user                 string
+	host                 string
 }
 
 func (jc *JiraConfig) GetUser() string {
@@ -28,9 +65,35 @@ func (jc *JiraConfig) GetHost() string {
 	return jc.host
 }
 
-func (jc *JiraConfig) Prepare() error {
-	if jc.EpicId == "" {
-		return fmt.Errorf("no jira epic id specified in config")
+func (jc *JiraConfig) Prepare(opts TemplateOptions) error {
+	switch jc.Edition {
+	case "", JiraEditionCloud, JiraEditionServer:
+	default:
+		return fmt.Errorf(`unknown jira edition: "%s" (expected one of: cloud, server)`, jc.Edition)
+	}
+
+	switch jc.Format {
+	case "", JiraFormatWiki:
+	case JiraFormatADF:
+		if jc.IsServer() {
+			return fmt.Errorf("jira adf format is supported only by jira cloud")
+		}
+	default:
+		return fmt.Errorf(`unknown jira format: "%s" (expected one of: wiki, adf)`, jc.Format)
+	}
+
+	err := validateCommentsMode("jira", jc.Comments)
+	if err != nil {
+		return err
+	}
+
+	jc.minCommentInterval, err = parseCommentInterval("jira", jc.MinCommentInterval)
+	if err != nil {
+		return err
+	}
+
+	if jc.EpicId != "" && jc.ParentId != "" {
+		return fmt.Errorf("specify either jira epic_id or parent_id, not both")
 	}
 
 	if jc.ProjectId == "" {
@@ -41,18 +104,84 @@ func (jc *JiraConfig) Prepare() error {
 		return fmt.Errorf("no jira task type id specified in config")
 	}
 
-	if jc.Template == "" {
+	if jc.Template == "" && jc.TemplateFile == "" {
 		return fmt.Errorf("no jira template specified in config")
 	}
 
+	tmpl, err := LoadTemplate("jira.template", jc.Template, jc.TemplateFile, opts)
+	if err != nil {
+		return err
+	}
+	jc.template = tmpl
+
+	if jc.TitleTemplate != "" {
+		tmpl, err = LoadTemplate("jira.title_template", jc.TitleTemplate, "", opts)
+		if err != nil {
+			return err
+		}
+		jc.titleTemplate = tmpl
+	}
+
+	jc.customFieldTemplates = make(map[string]*Template)
+	for id, value := range jc.CustomFields {
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		tmpl, err = LoadTemplate("jira.custom_fields."+id, text, "", opts)
+		if err != nil {
+			return err
+		}
+		jc.customFieldTemplates[id] = tmpl
+	}
+
+	return nil
+}
+
+// GetCustomFieldTemplate returns the template of a string custom field
+// value, nil for other values which are sent as they are
+func (jc *JiraConfig) GetCustomFieldTemplate(id string) *Template {
+	return jc.customFieldTemplates[id]
+}
+
+// configuredFields lists ids of optional issue fields set from the config
+func (jc *JiraConfig) configuredFields() []string {
+	var fields []string
+
+	if len(jc.Components) > 0 {
+		fields = append(fields, "components")
+	}
+	if jc.Priority != "" {
+		fields = append(fields, "priority")
+	}
+	if len(jc.FixVersions) > 0 {
+		fields = append(fields, "fixVersions")
+	}
+	if jc.AssigneeId != "" {
+		fields = append(fields, "assignee")
+	}
+	if jc.ReporterId != "" {
+		fields = append(fields, "reporter")
+	}
+	for id := range jc.CustomFields {
+		fields = append(fields, id)
+	}
+
+	slices.Sort(fields)
+	return fields
+}
+
+func (jc *JiraConfig) LoadCredentials() error {
 	token, present := os.LookupEnv("RSPEC_SANITY_JIRA_TOKEN")
 	if !present {
 		return fmt.Errorf("specify jira token under RSPEC_SANITY_JIRA_TOKEN env")
 	}
 	jc.token = token
 
+	// server personal access tokens are sent as bearer tokens, no user needed
 	user, present := os.LookupEnv("RSPEC_SANITY_JIRA_USER")
-	if !present {
+	if !present && !jc.IsServer() {
 		return fmt.Errorf("specify jira user under RSPEC_SANITY_JIRA_USER env")
 	}
 	jc.user = user
@@ -63,6 +192,28 @@ func (jc *JiraConfig) Prepare() error {
 	}
 	jc.host = host
 
-
 	return nil
 }
+
+// IsServer tells whether the config targets self-hosted JIRA (Server/Data Center)
+func (jc *JiraConfig) IsServer() bool {
+	return jc.Edition == JiraEditionServer
+}
+
+// UsesADF tells whether descriptions and comments are sent as Atlassian
+// Document Format (REST API v3)
+func (jc *JiraConfig) UsesADF() bool {
+	return jc.Format == JiraFormatADF
+}
+
+func (jc *JiraConfig) GetMinCommentInterval() time.Duration {
+	return jc.minCommentInterval
+}
+
+func (jc *JiraConfig) GetTemplate() *Template {
+	return jc.template
+}
+
+func (jc *JiraConfig) GetTitleTemplate() *Template {
+	return jc.titleTemplate
+}
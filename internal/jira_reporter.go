@@ -2,18 +2,31 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"log"
 
 	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/andygrunwald/go-jira/v2/onpremise"
 	"golang.org/x/exp/slices"
 )
 
+// JiraReporter talks to the REST API v2, which is shared by JIRA Cloud and
+// Server/Data Center; the on-premise client is only used for server endpoints
+// missing from the cloud client
 type JiraReporter struct {
-	config *JiraConfig
-	client *jira.Client
+	config        *JiraConfig
+	client        *jira.Client
+	server        *onpremise.Client
+	epicLinkField string
+	// deadline of all API calls, counted from Init (see report_timeout)
+	reportTimeout time.Duration
 }
 
 func NewJiraReporter(jc *JiraConfig) *JiraReporter {
@@ -23,9 +36,14 @@ func NewJiraReporter(jc *JiraConfig) *JiraReporter {
 }
 
 func (jr *JiraReporter) Init() error {
+	if jr.config.IsServer() {
+		return jr.initServer()
+	}
+
 	tp := jira.BasicAuthTransport{
-		Username: jr.config.GetUser(),
-		APIToken: jr.config.GetToken(),
+		Username:  jr.config.GetUser(),
+		APIToken:  jr.config.GetToken(),
+		Transport: NewRetryTransport(jr.reportTimeout),
 	}
 
 	client, err := jira.NewClient(
@@ -42,79 +60,391 @@ func (jr *JiraReporter) Init() error {
 	return nil
 }
 
-func (jr *JiraReporter) Verify() error {
-	log.Println("[jira] Verifying reporter")
-	testFlakies := []RspecExample{
-		{Id: "some/test-example.rb:1:2"},
-		{Id: "some/test-example.rb:10:2"},
+func (jr *JiraReporter) initServer() error {
+	tp := onpremise.BearerAuthTransport{
+		Token:     jr.config.GetToken(),
+		Transport: NewRetryTransport(jr.reportTimeout),
+	}
+
+	client, err := jira.NewClient(jr.config.GetHost(), tp.Client())
+	if err != nil {
+		return err
 	}
-	template, err := RenderTemplate(jr.config.Template, testFlakies)
+	jr.client = client
+
+	server, err := onpremise.NewClient(jr.config.GetHost(), tp.Client())
 	if err != nil {
 		return err
 	}
+	jr.server = server
 
-	issue, err := jr._createIssue("Test Issue", template, jr.config.Labels)
+	jr.epicLinkField = jr.config.EpicLinkField
+	if jr.epicLinkField != "" || jr.config.EpicId == "" {
+		return nil
+	}
 
+	fields, _, err := jr.client.Field.GetList(context.Background())
 	if err != nil {
 		return err
 	}
 
-	log.Printf("[jira] Created test issue: %s/browse/%s", jr.config.host, issue.Key)
+	idx := slices.IndexFunc(fields, func(f jira.Field) bool {
+		return f.Custom && f.Name == "Epic Link"
+	})
+	if idx == -1 {
+		return fmt.Errorf(`no "Epic Link" field found, specify epic_link_field in jira config`)
+	}
+	jr.epicLinkField = fields[idx].ID
 
 	return nil
 }
 
-func (jr *JiraReporter) ReportFlaky(flakies []RspecExample) error {
-	issueTitle := flakies[0].Filename()
+func (jr *JiraReporter) currentUser(ctx context.Context) (string, error) {
+	if jr.server != nil {
+		user, _, err := jr.server.User.GetSelf(ctx)
+		if err != nil {
+			return "", err
+		}
+		return user.DisplayName, nil
+	}
+
+	user, _, err := jr.client.User.GetCurrentUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	return user.DisplayName, nil
+}
+
+// epicClause matches issues belonging to the configured epic
+func (jr *JiraReporter) epicClause() string {
+	if jr.config.IsServer() {
+		return fmt.Sprintf(`"Epic Link" = %s`, jqlString(jr.config.EpicId))
+	}
 
-	query := fmt.Sprintf(
-		`project = %s AND ("Epic Link" = %s OR parent = %s) AND text ~ "\"%s\""`,
-		jr.config.ProjectId,
-		jr.config.EpicId,
-		jr.config.EpicId,
-		issueTitle,
-	)
+	return fmt.Sprintf(`("Epic Link" = %s OR parent = %s)`, jqlString(jr.config.EpicId), jqlString(jr.config.EpicId))
+}
 
-	issues, _, err := jr.client.Issue.Search(
-		context.Background(),
-		query,
-		&jira.SearchOptions{
-			MaxResults: 10,
+// scopeQuery restricts searches to issues managed by rspec-sanity: by the
+// configured JQL filter, scope label, epic or parent (in that order); just
+// the project for projects dedicated to flakies
+func (jr *JiraReporter) scopeQuery() string {
+	query := fmt.Sprintf("project = %s", jqlString(jr.config.ProjectId))
+
+	switch {
+	case jr.config.JqlFilter != "":
+		return fmt.Sprintf("%s AND (%s)", query, jr.config.JqlFilter)
+	case jr.config.ScopeLabel != "":
+		return fmt.Sprintf("%s AND labels = %s", query, jqlString(jr.config.ScopeLabel))
+	case jr.config.EpicId != "":
+		return fmt.Sprintf("%s AND %s", query, jr.epicClause())
+	case jr.config.ParentId != "":
+		return fmt.Sprintf("%s AND parent = %s", query, jqlString(jr.config.ParentId))
+	default:
+		return query
+	}
+}
+
+// labels returns labels of created issues
+func (jr *JiraReporter) labels() []string {
+	labels := slices.Clone(jr.config.Labels)
+
+	if jr.config.ScopeLabel != "" && !slices.Contains(labels, jr.config.ScopeLabel) {
+		labels = append(labels, jr.config.ScopeLabel)
+	}
+
+	return labels
+}
+
+// parentKey returns the parent of created issues, if any
+func (jr *JiraReporter) parentKey() string {
+	if jr.config.EpicId != "" {
+		return jr.config.EpicId
+	}
+
+	return jr.config.ParentId
+}
+
+// defaultFields lists ids of fields always set when creating an issue
+func (jr *JiraReporter) defaultFields() []string {
+	fields := []string{"summary", "description", "issuetype", "project", "labels"}
+
+	switch {
+	case jr.config.IsServer() && jr.config.EpicId != "":
+		return append(fields, jr.epicLinkField)
+	case jr.parentKey() != "":
+		return append(fields, "parent")
+	default:
+		return fields
+	}
+}
+
+// jiraUser references a user by account id (cloud) or username (server)
+func (jr *JiraReporter) jiraUser(id string) *jira.User {
+	if jr.config.IsServer() {
+		return &jira.User{Name: id}
+	}
+
+	return &jira.User{AccountID: id}
+}
+
+func (jr *JiraReporter) Verify(opts VerifyOptions) error {
+	log.Println("[jira] Verifying reporter")
+
+	ctx := context.Background()
+	checklist := NewChecklist("jira")
+
+	checklist.Check("template", func() (string, error) {
+		_, err := ExecuteTemplate(jr.config.GetTemplate(), verificationGroup)
+		return "", err
+	})
+
+	checklist.Check("credentials", func() (string, error) {
+		name, err := jr.currentUser(ctx)
+		if err != nil {
+			return "", err
+		}
+		return "authenticated as " + name, nil
+	})
+
+	var project *jira.Project
+
+	checklist.Check("project access", func() (string, error) {
+		var err error
+		project, _, err = jr.client.Project.Get(ctx, jr.config.ProjectId)
+		if err != nil {
+			return "", err
+		}
+		return project.Name, nil
+	})
+
+	if project != nil {
+		checklist.Check("issue type", func() (string, error) {
+			idx := slices.IndexFunc(project.IssueTypes, func(t jira.IssueType) bool {
+				return t.ID == jr.config.TaskTypeId
+			})
+			if idx == -1 {
+				return "", fmt.Errorf("issue type %s is not available in project %s", jr.config.TaskTypeId, jr.config.ProjectId)
+			}
+			return project.IssueTypes[idx].Name, nil
+		})
+	}
+
+	checklist.Check("create fields", func() (string, error) {
+		fields, err := jr.createFields(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return "", jiraCheckCreateFields(fields, jr.config, jr.defaultFields())
+	})
+
+	if parent := jr.parentKey(); parent != "" {
+		checklist.Check("parent issue", func() (string, error) {
+			issue, _, err := jr.client.Issue.Get(ctx, parent, nil)
+			if err != nil {
+				return "", err
+			}
+			return issue.Fields.Summary, nil
+		})
+	}
+
+	checklist.Check("issue search", func() (string, error) {
+		_, _, err := jr.client.Issue.Search(ctx, jr.scopeQuery(), &jira.SearchOptions{
+			MaxResults: 1,
 		})
+		return "", err
+	})
+
+	if opts.CreateIssue {
+		checklist.Check("test issue", jr.createTestIssue)
+	}
+
+	return checklist.Err()
+}
+
+// createFields returns metadata of fields available when creating issues of
+// the configured type, keyed by field id
+func (jr *JiraReporter) createFields(ctx context.Context) (map[string]any, error) {
+	if jr.config.IsServer() {
+		return jr.serverCreateFields(ctx)
+	}
+
+	meta, _, err := jr.client.Issue.GetCreateMeta(ctx, &jira.GetQueryOptions{
+		ProjectKeys: jr.config.ProjectId,
+		Expand:      "projects.issuetypes.fields",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	project := meta.GetProjectWithKey(jr.config.ProjectId)
+	if project == nil {
+		return nil, fmt.Errorf("project %s is not available for creating issues", jr.config.ProjectId)
+	}
+
+	idx := slices.IndexFunc(project.IssueTypes, func(t *jira.MetaIssueType) bool {
+		return t.Id == jr.config.TaskTypeId
+	})
+	if idx == -1 {
+		return nil, fmt.Errorf("issue type %s is not available for creating issues", jr.config.TaskTypeId)
+	}
+
+	return project.IssueTypes[idx].Fields, nil
+}
+
+// serverCreateFields uses the paginated createmeta endpoint of Data Center 9+,
+// createmeta with projects.issuetypes.fields expansion was removed there
+func (jr *JiraReporter) serverCreateFields(ctx context.Context) (map[string]any, error) {
+	fields := make(map[string]any)
+
+	for startAt := 0; ; {
+		apiEndpoint := fmt.Sprintf(
+			"rest/api/2/issue/createmeta/%s/issuetypes/%s?startAt=%d&maxResults=50",
+			url.PathEscape(jr.config.ProjectId),
+			url.PathEscape(jr.config.TaskTypeId),
+			startAt,
+		)
+
+		req, err := jr.client.NewRequest(ctx, http.MethodGet, apiEndpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Total  int              `json:"total"`
+			IsLast bool             `json:"isLast"`
+			Values []map[string]any `json:"values"`
+		}
+
+		_, err = jr.client.Do(req, &page)
+		if err != nil {
+			return nil, fmt.Errorf("issue type %s is not available for creating issues in project %s: %w", jr.config.TaskTypeId, jr.config.ProjectId, err)
+		}
+
+		for _, field := range page.Values {
+			if id, ok := field["fieldId"].(string); ok {
+				fields[id] = field
+			}
+		}
+
+		startAt += len(page.Values)
+		if page.IsLast || len(page.Values) == 0 || startAt >= page.Total {
+			return fields, nil
+		}
+	}
+}
+
+// createTestIssue creates a test issue and deletes it right away
+func (jr *JiraReporter) createTestIssue() (string, error) {
+	template, err := ExecuteTemplate(jr.config.GetTemplate(), verificationGroup)
+	if err != nil {
+		return "", err
+	}
+
+	issue, err := jr._createIssue(verificationGroup, "Test Issue", template, jr.labels())
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/browse/%s", jr.config.GetHost(), issue.Key)
+
+	_, err = jr.client.Issue.Delete(context.Background(), issue.Key)
+	if err != nil {
+		return "", fmt.Errorf("created %s but failed to delete it: %w", url, err)
+	}
+
+	return "created and deleted " + url, nil
+}
 
+func (jr *JiraReporter) ReportFlaky(group FlakyGroup) error {
+	issue, err := jr.findIssue(group.Key, Fingerprint(group.Key))
 	if err != nil {
 		log.Println("[jira] Error searching for issues")
 		return err
 	}
 
-	if len(issues) == 0 {
+	if issue == nil {
 		log.Println("No issues found, creating new one")
-		return jr.createIssue(flakies)
-	} else {
-		idx := slices.IndexFunc(issues, func(c jira.Issue) bool {
-			return c.Fields.Summary == flakies[0].Filename()
-		})
+		return jr.createIssue(group)
+	}
+
+	return jr.updateIssue(issue, group)
+}
+
+// findIssue looks up the issue by the fingerprint label; issues created before
+// fingerprints were introduced are matched by the exact summary (which used to
+// always be the group key). Returns nil when there is no match so a new issue
+// gets created.
+func (jr *JiraReporter) findIssue(title string, fingerprint string) (*jira.Issue, error) {
+	queries := []string{
+		fmt.Sprintf(
+			"project = %s AND labels = %s",
+			jqlString(jr.config.ProjectId),
+			jqlString(FingerprintLabel(fingerprint)),
+		),
+		fmt.Sprintf(
+			"%s AND text ~ %s",
+			jr.scopeQuery(),
+			jqlTextPhrase(title),
+		),
+	}
+
+	for _, query := range queries {
+		var found *jira.Issue
 
-		if idx == -1 {
-			log.Println("Can't find exact match, doing fallback")
-			idx = 0
+		// text search is fuzzy, so go through all pages until an exact match
+		err := jr.client.Issue.SearchPages(
+			context.Background(),
+			query,
+			&jira.SearchOptions{
+				MaxResults: 50,
+				Fields:     []string{"summary", "labels", "status", "created", "comment"},
+			},
+			func(c jira.Issue) error {
+				if slices.Contains(c.Fields.Labels, FingerprintLabel(fingerprint)) || c.Fields.Summary == title {
+					found = &c
+					return errJiraIssueFound
+				}
+				return nil
+			})
+
+		if found != nil {
+			return found, nil
 		}
 
-		return jr.addIssueComment(&issues[idx], flakies)
+		if err != nil {
+			return nil, err
+		}
 	}
+
+	return nil, nil
 }
 
+// errJiraIssueFound stops paginating search results
+var errJiraIssueFound = errors.New("issue found")
+
 type JiraSimpleComment struct {
 	Body string `json:"body"`
 }
 
+type JiraADFComment struct {
+	Body ADFNode `json:"body"`
+}
+
 // https://github.com/andygrunwald/go-jira/issues/604
 func (jr *JiraReporter) addComment(issue *jira.Issue, body string) error {
-	comment := JiraSimpleComment{
+	var comment any = JiraSimpleComment{
 		Body: body,
 	}
-
 	apiEndpoint := fmt.Sprintf("rest/api/2/issue/%s/comment", issue.ID)
+
+	if jr.config.UsesADF() {
+		comment = JiraADFComment{
+			Body: MarkdownToADF(body),
+		}
+		apiEndpoint = fmt.Sprintf("rest/api/3/issue/%s/comment", issue.ID)
+	}
+
 	req, err := jr.client.NewRequest(context.Background(), http.MethodPost, apiEndpoint, comment)
 
 	if err != nil {
@@ -128,8 +458,75 @@ func (jr *JiraReporter) addComment(issue *jira.Issue, body string) error {
 	return nil
 }
 
-func (jr *JiraReporter) addIssueComment(issue *jira.Issue, flakies []RspecExample) error {
-	body, err := RenderTemplate(jr.config.Template, flakies)
+// updateIssue records the recurrence: reopens the issue, updates the summary
+// and adds a comment (unless limited by the comments setting)
+func (jr *JiraReporter) updateIssue(issue *jira.Issue, group FlakyGroup) error {
+	state := jiraReportState{}
+	err := jr.getProperty(issue, jiraReportStateProperty, &state)
+	if err != nil {
+		return err
+	}
+
+	build := BuildKey(group.DetectedBuild())
+	if build != "" && state.Build == build {
+		log.Printf("[jira] Issue %s was already updated by build %s, skipping", issue.Key, build)
+		return nil
+	}
+
+	if jr.config.ReopenTransition != "" && jiraIssueDone(issue) {
+		err := jr.reopenIssue(issue)
+		if err != nil {
+			return err
+		}
+	}
+
+	if jr.config.Summary {
+		err := jr.updateSummary(issue, group)
+		if err != nil {
+			return err
+		}
+	}
+
+	if shouldComment(jr.config.Comments, jr.config.GetMinCommentInterval(), state.lastComment(issue), time.Now()) {
+		err := jr.addIssueComment(issue, group)
+		if err != nil {
+			return err
+		}
+		state.CommentedAt = time.Now().UTC()
+	} else {
+		log.Printf("[jira] Skipping comment on issue %s (comments = %s, min_comment_interval = %s)", issue.Key, jr.config.Comments, jr.config.MinCommentInterval)
+	}
+
+	if build != "" {
+		state.Build = build
+	}
+
+	return jr.setProperty(issue, jiraReportStateProperty, state)
+}
+
+// the last build that reported the issue and the time of the last comment
+// added by rspec-sanity are kept in an issue property, JIRA has no hidden
+// markers in comments
+const jiraReportStateProperty = "rspec-sanity-build"
+
+type jiraReportState struct {
+	Build       string    `json:"build"`
+	CommentedAt time.Time `json:"commented_at,omitzero"`
+}
+
+// lastComment is the time of the last comment added by rspec-sanity (replies
+// of people don't count); issues reported before it was tracked fall back to
+// the latest comment of any author
+func (s *jiraReportState) lastComment(issue *jira.Issue) time.Time {
+	if !s.CommentedAt.IsZero() {
+		return s.CommentedAt
+	}
+
+	return jiraLastOccurrence(issue)
+}
+
+func (jr *JiraReporter) addIssueComment(issue *jira.Issue, group FlakyGroup) error {
+	body, err := ExecuteTemplate(jr.config.GetTemplate(), group)
 	if err != nil {
 		return err
 	}
@@ -144,16 +541,63 @@ func (jr *JiraReporter) addIssueComment(issue *jira.Issue, flakies []RspecExampl
 	return nil
 }
 
-func (jr *JiraReporter) createIssue(flakies []RspecExample) error {
-	body, err := RenderTemplate(jr.config.Template, flakies)
+func jiraIssueDone(issue *jira.Issue) bool {
+	return issue.Fields != nil &&
+		issue.Fields.Status != nil &&
+		issue.Fields.Status.StatusCategory.Key == jira.StatusCategoryComplete
+}
+
+func (jr *JiraReporter) reopenIssue(issue *jira.Issue) error {
+	err := jr.transitionIssue(issue, jr.config.ReopenTransition)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[jira] Reopened issue: %s", issue.Key)
+
+	if jr.config.ReopenUnassign {
+		return jr.unassignIssue(issue)
+	}
+
+	return nil
+}
+
+// unassignIssue clears the assignee; go-jira omits empty account id so the
+// request is built by hand
+func (jr *JiraReporter) unassignIssue(issue *jira.Issue) error {
+	key := "accountId"
+	if jr.config.IsServer() {
+		key = "name"
+	}
+
+	apiEndpoint := fmt.Sprintf("rest/api/2/issue/%s/assignee", issue.ID)
+	req, err := jr.client.NewRequest(context.Background(), http.MethodPut, apiEndpoint, map[string]any{key: nil})
 	if err != nil {
 		return err
 	}
 
+	_, err = jr.client.Do(req, nil)
+	return err
+}
+
+func (jr *JiraReporter) createIssue(group FlakyGroup) error {
+	body, err := ExecuteTemplate(jr.config.GetTemplate(), group)
+	if err != nil {
+		return err
+	}
+
+	title, err := RenderTitle(jr.config.GetTitleTemplate(), group)
+	if err != nil {
+		return err
+	}
+
+	labels := append(jr.labels(), FingerprintLabel(Fingerprint(group.Key)))
+
 	newIssue, err := jr._createIssue(
-		flakies[0].Filename(),
+		group,
+		title,
 		body,
-		jr.config.Labels,
+		labels,
 	)
 
 	if err != nil {
@@ -162,14 +606,33 @@ func (jr *JiraReporter) createIssue(flakies []RspecExample) error {
 
 	log.Printf("[jira] Created new issue: %s", newIssue.Key)
 
-	return nil
+	if jr.config.Summary {
+		summary := OccurrenceSummary{}
+		summary.Record(group, group.DetectedBuild(), time.Now())
+
+		err = jr.writeSummary(newIssue, &summary)
+		if err != nil {
+			return err
+		}
+	}
+
+	// creation counts as the first comment
+	return jr.setProperty(newIssue, jiraReportStateProperty, jiraReportState{
+		Build:       BuildKey(group.DetectedBuild()),
+		CommentedAt: time.Now().UTC(),
+	})
 }
 
-func (jr *JiraReporter) _createIssue(title string, body string, labels []string) (*jira.Issue, error) {
+func (jr *JiraReporter) _createIssue(group FlakyGroup, title string, body string, labels []string) (*jira.Issue, error) {
 	if len(labels) == 0 {
 		labels = make([]string, 0)
 	}
 
+	customFields, err := jr.customFields(group)
+	if err != nil {
+		return nil, err
+	}
+
 	issue := jira.Issue{
 		Fields: &jira.IssueFields{
 			Description: body,
@@ -179,12 +642,43 @@ func (jr *JiraReporter) _createIssue(title string, body string, labels []string)
 			Project: jira.Project{
 				Key: jr.config.ProjectId,
 			},
-			Parent:  &jira.Parent{Key: jr.config.EpicId},
-			Summary: title,
-			Labels:  labels,
+			Summary:  title,
+			Labels:   labels,
+			Unknowns: customFields,
 		},
 	}
 
+	// server links issues to epics through the "Epic Link" custom field
+	if jr.config.IsServer() && jr.config.EpicId != "" {
+		customFields[jr.epicLinkField] = jr.config.EpicId
+	} else if parent := jr.parentKey(); parent != "" {
+		issue.Fields.Parent = &jira.Parent{Key: parent}
+	}
+
+	for _, name := range jr.config.Components {
+		issue.Fields.Components = append(issue.Fields.Components, &jira.Component{Name: name})
+	}
+
+	for _, name := range jr.config.FixVersions {
+		issue.Fields.FixVersions = append(issue.Fields.FixVersions, &jira.FixVersion{Name: name})
+	}
+
+	if jr.config.Priority != "" {
+		issue.Fields.Priority = &jira.Priority{Name: jr.config.Priority}
+	}
+
+	if jr.config.AssigneeId != "" {
+		issue.Fields.Assignee = jr.jiraUser(jr.config.AssigneeId)
+	}
+
+	if jr.config.ReporterId != "" {
+		issue.Fields.Reporter = jr.jiraUser(jr.config.ReporterId)
+	}
+
+	if jr.config.UsesADF() {
+		return jr.createADFIssue(&issue)
+	}
+
 	newIssue, _, err := jr.client.Issue.Create(
 		context.Background(),
 		&issue,
@@ -192,3 +686,402 @@ func (jr *JiraReporter) _createIssue(title string, body string, labels []string)
 
 	return newIssue, err
 }
+
+// createADFIssue creates the issue through REST API v3, which expects the
+// description as an ADF document instead of a string
+func (jr *JiraReporter) createADFIssue(issue *jira.Issue) (*jira.Issue, error) {
+	data, err := json.Marshal(issue.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]any)
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	fields["description"] = MarkdownToADF(issue.Fields.Description)
+
+	req, err := jr.client.NewRequest(context.Background(), http.MethodPost, "rest/api/3/issue", map[string]any{"fields": fields})
+	if err != nil {
+		return nil, err
+	}
+
+	newIssue := new(jira.Issue)
+	_, err = jr.client.Do(req, newIssue)
+	if err != nil {
+		return nil, err
+	}
+
+	return newIssue, nil
+}
+
+// customFields renders templated (string) custom field values, other values
+// are sent as configured
+func (jr *JiraReporter) customFields(group FlakyGroup) (map[string]any, error) {
+	fields := make(map[string]any, len(jr.config.CustomFields))
+
+	for id, value := range jr.config.CustomFields {
+		tmpl := jr.config.GetCustomFieldTemplate(id)
+		if tmpl == nil {
+			fields[id] = value
+			continue
+		}
+
+		rendered, err := ExecuteTemplate(tmpl, group)
+		if err != nil {
+			return nil, err
+		}
+		fields[id] = strings.TrimSpace(rendered)
+	}
+
+	return fields, nil
+}
+
+// jiraCheckCreateFields validates configured fields against the create-meta
+// of the issue type
+func jiraCheckCreateFields(fields map[string]any, config *JiraConfig, defaults []string) error {
+	var errs []error
+
+	configured := config.configuredFields()
+
+	for _, id := range configured {
+		if _, ok := fields[id]; !ok {
+			errs = append(errs, fmt.Errorf(`field "%s" is not available when creating issues`, id))
+		}
+	}
+
+	values := map[string][]string{
+		"components":  config.Components,
+		"fixVersions": config.FixVersions,
+	}
+	if config.Priority != "" {
+		values["priority"] = []string{config.Priority}
+	}
+
+	for _, id := range []string{"components", "fixVersions", "priority"} {
+		allowed := jiraAllowedValues(fields[id])
+		if allowed == nil {
+			continue
+		}
+
+		for _, value := range values[id] {
+			if !slices.Contains(allowed, value) {
+				errs = append(errs, fmt.Errorf(`"%s" is not an allowed %s value`, value, id))
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(fields))
+	for id := range fields {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		meta, _ := fields[id].(map[string]any)
+		if meta["required"] != true || meta["hasDefaultValue"] == true {
+			continue
+		}
+
+		if !slices.Contains(defaults, id) && !slices.Contains(configured, id) {
+			errs = append(errs, fmt.Errorf(`required field "%s" (%v) is not set`, id, meta["name"]))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// jiraAllowedValues returns names of allowed field values, nil when the field
+// doesn't restrict them
+func jiraAllowedValues(field any) []string {
+	meta, _ := field.(map[string]any)
+	allowedValues, ok := meta["allowedValues"].([]any)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(allowedValues))
+	for _, value := range allowedValues {
+		if value, ok := value.(map[string]any); ok {
+			if name, ok := value["name"].(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// summary data is kept in an issue property, the description only shows it
+const jiraSummaryProperty = "rspec-sanity-summary"
+
+func (jr *JiraReporter) updateSummary(issue *jira.Issue, group FlakyGroup) error {
+	summary, err := jr.loadSummary(issue)
+	if err != nil {
+		return err
+	}
+
+	summary.Record(group, group.DetectedBuild(), time.Now())
+
+	return jr.writeSummary(issue, &summary)
+}
+
+func (jr *JiraReporter) loadSummary(issue *jira.Issue) (OccurrenceSummary, error) {
+	summary := OccurrenceSummary{}
+	err := jr.getProperty(issue, jiraSummaryProperty, &summary)
+
+	return summary, err
+}
+
+func (jr *JiraReporter) writeSummary(issue *jira.Issue, summary *OccurrenceSummary) error {
+	err := jr.setProperty(issue, jiraSummaryProperty, summary)
+	if err != nil {
+		return err
+	}
+
+	if jr.config.UsesADF() {
+		return jr.updateADFDescription(issue, summary)
+	}
+
+	return jr.updateWikiDescription(issue, summary)
+}
+
+// getProperty reads the issue property into v, leaving it untouched when the
+// property isn't set
+func (jr *JiraReporter) getProperty(issue *jira.Issue, key string, v any) error {
+	property := struct {
+		Value any `json:"value"`
+	}{Value: v}
+
+	apiEndpoint := fmt.Sprintf("rest/api/2/issue/%s/properties/%s", issue.ID, key)
+	req, err := jr.client.NewRequest(context.Background(), http.MethodGet, apiEndpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := jr.client.Do(req, &property)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	return err
+}
+
+func (jr *JiraReporter) setProperty(issue *jira.Issue, key string, v any) error {
+	apiEndpoint := fmt.Sprintf("rest/api/2/issue/%s/properties/%s", issue.ID, key)
+	req, err := jr.client.NewRequest(context.Background(), http.MethodPut, apiEndpoint, v)
+	if err != nil {
+		return err
+	}
+
+	_, err = jr.client.Do(req, nil)
+	return err
+}
+
+func (jr *JiraReporter) updateWikiDescription(issue *jira.Issue, summary *OccurrenceSummary) error {
+	current := struct {
+		Fields struct {
+			Description string `json:"description"`
+		} `json:"fields"`
+	}{}
+
+	err := jr.issueFields("rest/api/2", issue, &current)
+	if err != nil {
+		return err
+	}
+
+	description := ReplaceJiraWikiSummary(current.Fields.Description, summary)
+
+	return jr.updateDescription("rest/api/2", issue, description)
+}
+
+func (jr *JiraReporter) updateADFDescription(issue *jira.Issue, summary *OccurrenceSummary) error {
+	current := struct {
+		Fields struct {
+			Description *ADFNode `json:"description"`
+		} `json:"fields"`
+	}{}
+
+	err := jr.issueFields("rest/api/3", issue, &current)
+	if err != nil {
+		return err
+	}
+
+	doc := ADFNode{Type: "doc", Version: 1}
+	if current.Fields.Description != nil {
+		doc = *current.Fields.Description
+	}
+
+	return jr.updateDescription("rest/api/3", issue, ReplaceADFSummary(doc, summary))
+}
+
+func (jr *JiraReporter) issueFields(api string, issue *jira.Issue, v any) error {
+	apiEndpoint := fmt.Sprintf("%s/issue/%s?fields=description", api, issue.ID)
+	req, err := jr.client.NewRequest(context.Background(), http.MethodGet, apiEndpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = jr.client.Do(req, v)
+	return err
+}
+
+func (jr *JiraReporter) updateDescription(api string, issue *jira.Issue, description any) error {
+	apiEndpoint := fmt.Sprintf("%s/issue/%s", api, issue.ID)
+	payload := map[string]any{"fields": map[string]any{"description": description}}
+
+	req, err := jr.client.NewRequest(context.Background(), http.MethodPut, apiEndpoint, payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = jr.client.Do(req, nil)
+	return err
+}
+
+const jiraCommentTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+func (jr *JiraReporter) CloseStale(opts StaleOptions) error {
+	if jr.config.CloseTransition == "" {
+		return fmt.Errorf("specify close_transition in jira config to close stale tickets")
+	}
+
+	query := fmt.Sprintf("%s AND statusCategory != Done", jr.scopeQuery())
+
+	type staleIssue struct {
+		issue       jira.Issue
+		fingerprint string
+		lastSeen    time.Time
+	}
+
+	// closed issues drop out of the search results and would shift the
+	// following pages, so issues are closed once every page is fetched
+	var candidates []staleIssue
+	var errs []error
+
+	err := jr.client.Issue.SearchPages(
+		context.Background(),
+		query,
+		&jira.SearchOptions{
+			MaxResults: 50,
+			Fields:     []string{"summary", "labels", "created", "comment"},
+		},
+		func(issue jira.Issue) error {
+			fingerprint := jiraFingerprint(&issue)
+			if fingerprint == "" {
+				return nil
+			}
+
+			lastSeen, err := jr.lastOccurrence(&issue)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", issue.Key, err))
+				return nil
+			}
+
+			candidates = append(candidates, staleIssue{issue: issue, fingerprint: fingerprint, lastSeen: lastSeen})
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fingerprints := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		fingerprints[i] = candidate.fingerprint
+	}
+	opts.MatchSpecFiles(fingerprints)
+
+	for _, candidate := range candidates {
+		issue := candidate.issue
+
+		reason := opts.StaleReason(candidate.fingerprint, candidate.lastSeen)
+		if reason == "" {
+			continue
+		}
+
+		if opts.DryRun {
+			log.Printf("[jira] Would close issue %s %s: %s", issue.Key, issue.Fields.Summary, reason)
+			continue
+		}
+
+		err := jr.addComment(&issue, staleComment(reason))
+		if err == nil {
+			err = jr.transitionIssue(&issue, jr.config.CloseTransition)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", issue.Key, err))
+			continue
+		}
+
+		log.Printf("[jira] Closed issue %s %s: %s", issue.Key, issue.Fields.Summary, reason)
+	}
+
+	return errors.Join(errs...)
+}
+
+func jiraFingerprint(issue *jira.Issue) string {
+	for _, label := range issue.Fields.Labels {
+		if strings.HasPrefix(label, FingerprintLabel("")) {
+			return strings.TrimPrefix(label, FingerprintLabel(""))
+		}
+	}
+
+	return ""
+}
+
+// lastOccurrence reads the occurrence summary property - recurrences aren't
+// always commented on (see comments setting) - falling back to the latest
+// comment for issues without one
+func (jr *JiraReporter) lastOccurrence(issue *jira.Issue) (time.Time, error) {
+	summary, err := jr.loadSummary(issue)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if summary.Count > 0 {
+		return summary.LastSeen, nil
+	}
+
+	return jiraLastOccurrence(issue), nil
+}
+
+// jiraLastOccurrence is the time of the latest comment or of the issue
+// creation
+func jiraLastOccurrence(issue *jira.Issue) time.Time {
+	lastSeen := time.Time(issue.Fields.Created)
+
+	if issue.Fields.Comments == nil {
+		return lastSeen
+	}
+
+	for _, comment := range issue.Fields.Comments.Comments {
+		created, err := time.Parse(jiraCommentTimeLayout, comment.Created)
+		if err == nil && created.After(lastSeen) {
+			lastSeen = created
+		}
+	}
+
+	return lastSeen
+}
+
+// transitionIssue runs workflow transition given by its name or id
+func (jr *JiraReporter) transitionIssue(issue *jira.Issue, transition string) error {
+	transitions, _, err := jr.client.Issue.GetTransitions(context.Background(), issue.Key)
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(transitions, func(t jira.Transition) bool {
+		return t.ID == transition || strings.EqualFold(t.Name, transition)
+	})
+
+	if idx == -1 {
+		return fmt.Errorf(`transition "%s" is not available for issue %s`, transition, issue.Key)
+	}
+
+	_, err = jr.client.Issue.DoTransition(context.Background(), issue.Key, transitions[idx].ID)
+	return err
+}
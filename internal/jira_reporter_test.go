@@ -0,0 +1,243 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJiraIssueDone(t *testing.T) {
+	assert.False(t, jiraIssueDone(&jira.Issue{Fields: &jira.IssueFields{}}))
+
+	assert.False(t, jiraIssueDone(&jira.Issue{Fields: &jira.IssueFields{
+		Status: &jira.Status{StatusCategory: jira.StatusCategory{Key: jira.StatusCategoryInProgress}},
+	}}))
+
+	assert.True(t, jiraIssueDone(&jira.Issue{Fields: &jira.IssueFields{
+		Status: &jira.Status{StatusCategory: jira.StatusCategory{Key: jira.StatusCategoryComplete}},
+	}}))
+}
+
+// newTestJiraReporter points the reporter's client to a test server
+func newTestJiraReporter(t *testing.T, config *JiraConfig, handler http.Handler) *JiraReporter {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	reporter := NewJiraReporter(config)
+
+	var err error
+	reporter.client, err = jira.NewClient(server.URL, nil)
+	assert.NoError(t, err)
+
+	return reporter
+}
+
+func TestJiraCheckCreateFields(t *testing.T) {
+	fields := map[string]any{
+		"summary":           map[string]any{"required": true, "name": "Summary"},
+		"priority":          map[string]any{"name": "Priority", "allowedValues": []any{map[string]any{"name": "High"}, map[string]any{"name": "Low"}}},
+		"components":        map[string]any{"name": "Component/s", "allowedValues": []any{map[string]any{"name": "backend"}}},
+		"customfield_10010": map[string]any{"required": true, "name": "Team"},
+		"customfield_10020": map[string]any{"required": true, "name": "Severity", "hasDefaultValue": true},
+	}
+
+	config := &JiraConfig{
+		Priority:     "High",
+		Components:   []string{"backend"},
+		CustomFields: map[string]any{"customfield_10010": "platform"},
+	}
+	assert.NoError(t, jiraCheckCreateFields(fields, config, NewJiraReporter(config).defaultFields()))
+
+	config = &JiraConfig{
+		Priority:   "Urgent",
+		Components: []string{"backend"},
+		AssigneeId: "5b10a2844c20165700ede21g",
+	}
+	err := jiraCheckCreateFields(fields, config, NewJiraReporter(config).defaultFields())
+	assert.ErrorContains(t, err, `field "assignee" is not available when creating issues`)
+	assert.ErrorContains(t, err, `"Urgent" is not an allowed priority value`)
+	assert.ErrorContains(t, err, `required field "customfield_10010" (Team) is not set`)
+	assert.NotContains(t, err.Error(), "customfield_10020")
+}
+
+func TestJiraCustomFields(t *testing.T) {
+	config := &JiraConfig{
+		EpicId:     "PROD-1",
+		ProjectId:  "PROD",
+		TaskTypeId: "10001",
+		Template:   "{{ .GroupKey }}",
+		CustomFields: map[string]any{
+			"customfield_10010": "{{ .GroupKey }} ",
+			"customfield_10020": map[string]any{"value": "High"},
+		},
+	}
+	assert.NoError(t, config.Prepare(TemplateOptions{}))
+
+	fields, err := NewJiraReporter(config).customFields(FlakyGroup{Key: "./spec/flaky_spec.rb"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"customfield_10010": "./spec/flaky_spec.rb",
+		"customfield_10020": map[string]any{"value": "High"},
+	}, fields)
+}
+
+func TestJiraServerEdition(t *testing.T) {
+	cloud := NewJiraReporter(&JiraConfig{EpicId: "PROD-1"})
+	assert.Equal(t, `("Epic Link" = "PROD-1" OR parent = "PROD-1")`, cloud.epicClause())
+	assert.Equal(t, &jira.User{AccountID: "5b10a2844c20165700ede21g"}, cloud.jiraUser("5b10a2844c20165700ede21g"))
+	assert.Contains(t, cloud.defaultFields(), "parent")
+
+	server := NewJiraReporter(&JiraConfig{EpicId: "PROD-1", Edition: JiraEditionServer})
+	server.epicLinkField = "customfield_10100"
+	assert.Equal(t, `"Epic Link" = "PROD-1"`, server.epicClause())
+	assert.Equal(t, &jira.User{Name: "jdoe"}, server.jiraUser("jdoe"))
+	assert.Contains(t, server.defaultFields(), "customfield_10100")
+	assert.NotContains(t, server.defaultFields(), "parent")
+}
+
+func TestJiraScopeQuery(t *testing.T) {
+	reporter := NewJiraReporter(&JiraConfig{ProjectId: "FLAKY"})
+	assert.Equal(t, `project = "FLAKY"`, reporter.scopeQuery())
+	assert.Equal(t, "", reporter.parentKey())
+	assert.NotContains(t, reporter.defaultFields(), "parent")
+
+	reporter = NewJiraReporter(&JiraConfig{ProjectId: "PROD", ParentId: "PROD-7"})
+	assert.Equal(t, `project = "PROD" AND parent = "PROD-7"`, reporter.scopeQuery())
+	assert.Equal(t, "PROD-7", reporter.parentKey())
+
+	reporter = NewJiraReporter(&JiraConfig{ProjectId: "PROD", EpicId: "PROD-1", ScopeLabel: "flaky-spec", Labels: []string{"ci"}})
+	assert.Equal(t, `project = "PROD" AND labels = "flaky-spec"`, reporter.scopeQuery())
+	assert.Equal(t, []string{"ci", "flaky-spec"}, reporter.labels())
+
+	reporter = NewJiraReporter(&JiraConfig{ProjectId: "PROD", JqlFilter: "component = CI", ScopeLabel: "flaky-spec"})
+	assert.Equal(t, `project = "PROD" AND (component = CI)`, reporter.scopeQuery())
+}
+
+func TestJiraConfigParent(t *testing.T) {
+	config := &JiraConfig{ProjectId: "PROD", TaskTypeId: "10001", Template: "{{ .GroupKey }}"}
+	assert.NoError(t, config.Prepare(TemplateOptions{}))
+
+	config.EpicId = "PROD-1"
+	config.ParentId = "PROD-7"
+	assert.EqualError(t, config.Prepare(TemplateOptions{}), "specify either jira epic_id or parent_id, not both")
+}
+
+func TestJiraConfigFormat(t *testing.T) {
+	config := &JiraConfig{ProjectId: "PROD", TaskTypeId: "10001", Template: "{{ .GroupKey }}", Format: JiraFormatADF}
+	assert.NoError(t, config.Prepare(TemplateOptions{}))
+	assert.True(t, config.UsesADF())
+
+	config.Edition = JiraEditionServer
+	assert.EqualError(t, config.Prepare(TemplateOptions{}), "jira adf format is supported only by jira cloud")
+
+	config.Format = "html"
+	assert.Error(t, config.Prepare(TemplateOptions{}))
+}
+
+func TestJiraServerCreateFields(t *testing.T) {
+	pages := map[string]string{
+		"0": `{"startAt": 0, "maxResults": 2, "total": 3, "isLast": false, "values": [
+			{"fieldId": "summary", "name": "Summary", "required": true},
+			{"fieldId": "priority", "name": "Priority", "required": false, "allowedValues": [{"name": "High"}]}
+		]}`,
+		"2": `{"startAt": 2, "maxResults": 2, "total": 3, "isLast": true, "values": [
+			{"fieldId": "customfield_10010", "name": "Team", "required": true}
+		]}`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/2/issue/createmeta/PROD/issuetypes/10001", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(pages[r.URL.Query().Get("startAt")]))
+	})
+
+	config := &JiraConfig{Edition: JiraEditionServer, ProjectId: "PROD", TaskTypeId: "10001", Priority: "Low"}
+	reporter := newTestJiraReporter(t, config, mux)
+
+	fields, err := reporter.createFields(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(fields))
+	assert.Contains(t, fields, "customfield_10010")
+
+	err = jiraCheckCreateFields(fields, config, reporter.defaultFields())
+	assert.ErrorContains(t, err, `"Low" is not an allowed priority value`)
+	assert.ErrorContains(t, err, `required field "customfield_10010" (Team) is not set`)
+
+	config.TaskTypeId = "10002"
+	_, err = reporter.createFields(context.Background())
+	assert.ErrorContains(t, err, "issue type 10002 is not available for creating issues in project PROD")
+}
+
+func TestJiraLastOccurrence(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	summary := OccurrenceSummary{Count: 5, LastSeen: now.Add(-24 * time.Hour)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/2/issue/1/properties/"+jiraSummaryProperty, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"key": jiraSummaryProperty, "value": summary})
+	})
+
+	reporter := newTestJiraReporter(t, &JiraConfig{ProjectId: "PROD"}, mux)
+
+	// the last comment is old as recurrences are no longer commented on
+	comments := &jira.Comments{Comments: []*jira.Comment{{Created: now.Add(-60 * 24 * time.Hour).Format(jiraCommentTimeLayout)}}}
+	issue := &jira.Issue{ID: "1", Fields: &jira.IssueFields{
+		Created:  jira.Time(now.Add(-90 * 24 * time.Hour)),
+		Comments: comments,
+	}}
+
+	lastSeen, err := reporter.lastOccurrence(issue)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), lastSeen)
+
+	// issues without summary property
+	issue.ID = "2"
+	lastSeen, err = reporter.lastOccurrence(issue)
+	assert.NoError(t, err)
+	assert.True(t, now.Add(-60*24*time.Hour).Equal(lastSeen))
+}
+
+func TestJiraUpdateIssueMinCommentInterval(t *testing.T) {
+	clearBuildEnv(t)
+
+	now := time.Now().UTC()
+	state := jiraReportState{CommentedAt: now.Add(-2 * time.Hour)}
+	comments := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/2/issue/1/properties/"+jiraReportStateProperty, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"value": state})
+	})
+	mux.HandleFunc("PUT /rest/api/2/issue/1/properties/"+jiraReportStateProperty, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&state)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/1/comment", func(w http.ResponseWriter, r *http.Request) {
+		comments++
+		w.Write([]byte("{}"))
+	})
+
+	template, err := ParseTemplate("template", "report", TemplateOptions{})
+	assert.NoError(t, err)
+
+	config := &JiraConfig{ProjectId: "PROD", minCommentInterval: time.Hour, template: template}
+	reporter := newTestJiraReporter(t, config, mux)
+
+	// a reply of a person 10 minutes ago doesn't reset the interval
+	issue := &jira.Issue{ID: "1", Key: "PROD-1", Fields: &jira.IssueFields{
+		Comments: &jira.Comments{Comments: []*jira.Comment{{Created: now.Add(-10 * time.Minute).Format(jiraCommentTimeLayout)}}},
+	}}
+
+	assert.NoError(t, reporter.updateIssue(issue, FlakyGroup{Key: "spec/flaky_spec.rb"}))
+	assert.Equal(t, 1, comments)
+	assert.WithinDuration(t, now, state.CommentedAt, time.Minute)
+
+	// the interval counts from the comment just added
+	assert.NoError(t, reporter.updateIssue(issue, FlakyGroup{Key: "spec/flaky_spec.rb"}))
+	assert.Equal(t, 1, comments)
+}
@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"strings"
+)
+
+// characters with special meaning in JQL text search (Lucene syntax)
+var jqlTextReplacer = strings.NewReplacer(
+	`\`, `\\`, `+`, `\+`, `-`, `\-`, `&`, `\&`, `|`, `\|`, `!`, `\!`, `(`, `\(`, `)`, `\)`,
+	`{`, `\{`, `}`, `\}`, `[`, `\[`, `]`, `\]`, `^`, `\^`, `~`, `\~`, `*`, `\*`, `?`, `\?`,
+	`:`, `\:`, `"`, `\"`,
+)
+
+var jqlStringReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// jqlString quotes value as a JQL string literal
+func jqlString(value string) string {
+	return `"` + jqlStringReplacer.Replace(value) + `"`
+}
+
+// jqlTextPhrase quotes value for an exact phrase search with the ~ operator
+func jqlTextPhrase(value string) string {
+	return jqlString(`"` + jqlTextReplacer.Replace(value) + `"`)
+}
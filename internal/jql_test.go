@@ -0,0 +1,17 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJqlString(t *testing.T) {
+	assert.Equal(t, `"PROD"`, jqlString("PROD"))
+	assert.Equal(t, `"say \"hi\" \\ bye"`, jqlString(`say "hi" \ bye`))
+}
+
+func TestJqlTextPhrase(t *testing.T) {
+	assert.Equal(t, `"\"./spec/models/user_spec.rb\""`, jqlTextPhrase("./spec/models/user_spec.rb"))
+	assert.Equal(t, `"\"./spec/a\\[1\\:2\\] \\\"quoted\\\"\""`, jqlTextPhrase(`./spec/a[1:2] "quoted"`))
+}
@@ -2,20 +2,19 @@ package internal
 
 import "log"
 
-
-type NullReporter struct {}
+type NullReporter struct{}
 
 func (r *NullReporter) Init() error {
 	log.Println("[null] No reporter configured, skipping init")
 	return nil
 }
 
-func (r *NullReporter) Verify() error {
+func (r *NullReporter) Verify(opts VerifyOptions) error {
 	log.Println("[null] No reporter configured, skipping verification")
 	return nil
 }
 
-func (r *NullReporter) ReportFlaky(flakies []RspecExample) error {
-	log.Printf("[null] No reporter configured, skipping flaky report: %s\n", flakies[0].Id)
+func (r *NullReporter) ReportFlaky(group FlakyGroup) error {
+	log.Printf("[null] No reporter configured, skipping flaky report: %s\n", group.Key)
 	return nil
 }
@@ -0,0 +1,226 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Values of the `comments` reporter setting
+const (
+	CommentsAlways = "always"
+	CommentsNever  = "never"
+	CommentsDaily  = "daily"
+)
+
+func validateCommentsMode(reporter string, mode string) error {
+	switch mode {
+	case "", CommentsAlways, CommentsNever, CommentsDaily:
+		return nil
+	default:
+		return fmt.Errorf(`unknown %s comments value: "%s" (expected one of: always, never, daily)`, reporter, mode)
+	}
+}
+
+func parseCommentInterval(reporter string, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		return 0, fmt.Errorf(`invalid %s min_comment_interval: "%s" (expected a duration, eg. "6h")`, reporter, value)
+	}
+
+	return interval, nil
+}
+
+// shouldComment tells whether a recurrence should be commented on given the
+// time of the latest comment (or of the issue creation); "daily" is a
+// shorthand for a 24h minimal interval
+func shouldComment(mode string, minInterval time.Duration, lastComment time.Time, now time.Time) bool {
+	if mode == CommentsNever {
+		return false
+	}
+
+	if mode == CommentsDaily && minInterval < 24*time.Hour {
+		minInterval = 24 * time.Hour
+	}
+
+	return now.Sub(lastComment) >= minInterval
+}
+
+// OccurrenceSummary is maintained in the issue body and updated in place on
+// every report
+type OccurrenceSummary struct {
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Examples  []string  `json:"examples"`
+	Branches  []string  `json:"branches,omitempty"`
+	LastBuild string    `json:"last_build,omitempty"`
+}
+
+func (s *OccurrenceSummary) Record(group FlakyGroup, build Build, now time.Time) {
+	now = now.UTC()
+
+	if s.Count == 0 {
+		s.FirstSeen = now
+	}
+	s.Count++
+	s.LastSeen = now
+
+	for _, example := range group.Examples {
+		if !slices.Contains(s.Examples, example.Id) {
+			s.Examples = append(s.Examples, example.Id)
+		}
+	}
+
+	if build.Branch != "" && !slices.Contains(s.Branches, build.Branch) {
+		s.Branches = append(s.Branches, build.Branch)
+	}
+
+	s.LastBuild = BuildKey(build)
+}
+
+func (s *OccurrenceSummary) lines(code func(string) string) []string {
+	format := "2006-01-02 15:04 UTC"
+	lines := []string{
+		fmt.Sprintf("Occurrences: %d", s.Count),
+		"First seen: " + s.FirstSeen.Format(format),
+		"Last seen: " + s.LastSeen.Format(format),
+	}
+
+	if len(s.Branches) > 0 {
+		lines = append(lines, "Branches: "+joinMapped(s.Branches, code))
+	}
+
+	return append(lines, "Examples: "+joinMapped(s.Examples, code))
+}
+
+func joinMapped(values []string, fn func(string) string) string {
+	mapped := make([]string, len(values))
+	for i, value := range values {
+		mapped[i] = fn(value)
+	}
+	return strings.Join(mapped, ", ")
+}
+
+const (
+	summaryMarkerStart = "<!-- rspec-sanity:summary "
+	summaryMarkerEnd   = "<!-- rspec-sanity:summary-end -->"
+)
+
+var summarySectionRegexp = regexp.MustCompile(`(?s)<!-- rspec-sanity:summary (\{.*?\}) -->.*?<!-- rspec-sanity:summary-end -->\n*`)
+
+// Markdown renders the summary section with its data embedded in a hidden
+// HTML comment
+func (s *OccurrenceSummary) Markdown() string {
+	data, _ := json.Marshal(s)
+
+	var b strings.Builder
+	b.WriteString(summaryMarkerStart + string(data) + " -->\n")
+	b.WriteString("**Flaky summary** (maintained by rspec-sanity)\n\n")
+	for _, line := range s.lines(func(v string) string { return "`" + v + "`" }) {
+		b.WriteString("- " + line + "\n")
+	}
+	b.WriteString(summaryMarkerEnd + "\n\n")
+
+	return b.String()
+}
+
+// ParseMarkdownSummary reads the summary embedded in body, zero value when
+// there is none
+func ParseMarkdownSummary(body string) OccurrenceSummary {
+	var summary OccurrenceSummary
+
+	match := summarySectionRegexp.FindStringSubmatch(body)
+	if match != nil {
+		_ = json.Unmarshal([]byte(match[1]), &summary)
+	}
+
+	return summary
+}
+
+// ReplaceMarkdownSummary updates the summary section of body in place or
+// prepends it
+func ReplaceMarkdownSummary(body string, summary *OccurrenceSummary) string {
+	section := summary.Markdown()
+
+	if summarySectionRegexp.MatchString(body) {
+		return summarySectionRegexp.ReplaceAllLiteralString(body, section)
+	}
+
+	return section + body
+}
+
+const jiraSummaryPanelTitle = "rspec-sanity summary"
+
+var jiraSummaryPanelRegexp = regexp.MustCompile(`(?s)\{panel:title=` + jiraSummaryPanelTitle + `\}.*?\{panel\}\n*`)
+
+// JiraWiki renders the summary as a JIRA wiki markup panel
+func (s *OccurrenceSummary) JiraWiki() string {
+	var b strings.Builder
+	b.WriteString("{panel:title=" + jiraSummaryPanelTitle + "}\n")
+	for _, line := range s.lines(func(v string) string { return "{{" + jiraEscape(v) + "}}" }) {
+		b.WriteString("* " + line + "\n")
+	}
+	b.WriteString("{panel}\n\n")
+
+	return b.String()
+}
+
+// ReplaceJiraWikiSummary updates the summary panel of description in place
+// or prepends it
+func ReplaceJiraWikiSummary(description string, summary *OccurrenceSummary) string {
+	panel := summary.JiraWiki()
+
+	if jiraSummaryPanelRegexp.MatchString(description) {
+		return jiraSummaryPanelRegexp.ReplaceAllLiteralString(description, panel)
+	}
+
+	return panel + description
+}
+
+// ADF renders the summary as an info panel; ADF has no hidden markers so the
+// panel is recognized by its heading
+func (s *OccurrenceSummary) ADF() ADFNode {
+	markdown := "**" + jiraSummaryPanelTitle + "**\n"
+	for _, line := range s.lines(func(v string) string { return "`" + v + "`" }) {
+		markdown += "- " + line + "\n"
+	}
+
+	return ADFNode{
+		Type:    "panel",
+		Attrs:   map[string]any{"panelType": "info"},
+		Content: MarkdownToADF(markdown).Content,
+	}
+}
+
+// ReplaceADFSummary updates the summary panel of the document in place or
+// prepends it
+func ReplaceADFSummary(doc ADFNode, summary *OccurrenceSummary) ADFNode {
+	panel := summary.ADF()
+
+	for i, node := range doc.Content {
+		if isADFSummaryPanel(node) {
+			doc.Content[i] = panel
+			return doc
+		}
+	}
+
+	doc.Content = append([]ADFNode{panel}, doc.Content...)
+	return doc
+}
+
+func isADFSummaryPanel(node ADFNode) bool {
+	if node.Type != "panel" || len(node.Content) == 0 || len(node.Content[0].Content) == 0 {
+		return false
+	}
+
+	return node.Content[0].Content[0].Text == jiraSummaryPanelTitle
+}
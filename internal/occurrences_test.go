@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldComment(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	assert.True(t, shouldComment("", 0, now, now))
+	assert.True(t, shouldComment(CommentsAlways, 0, time.Time{}, now))
+	assert.False(t, shouldComment(CommentsNever, 0, time.Time{}, now))
+	assert.False(t, shouldComment(CommentsDaily, 0, now.Add(-23*time.Hour), now))
+	assert.True(t, shouldComment(CommentsDaily, 0, now.Add(-24*time.Hour), now))
+	assert.False(t, shouldComment(CommentsDaily, 48*time.Hour, now.Add(-24*time.Hour), now))
+	assert.False(t, shouldComment(CommentsAlways, 6*time.Hour, now.Add(-5*time.Hour), now))
+	assert.True(t, shouldComment(CommentsAlways, 6*time.Hour, now.Add(-6*time.Hour), now))
+
+	assert.Error(t, validateCommentsMode("github", "hourly"))
+
+	interval, err := parseCommentInterval("github", "6h")
+	assert.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, interval)
+
+	_, err = parseCommentInterval("github", "daily")
+	assert.Error(t, err)
+}
+
+func TestOccurrenceSummaryRecord(t *testing.T) {
+	first := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	group := FlakyGroup{Key: "./spec/flaky_spec.rb", Examples: []RspecExample{{Id: "./spec/flaky_spec.rb[1:1]"}}}
+
+	summary := OccurrenceSummary{}
+	summary.Record(group, Build{Branch: "main"}, first)
+	summary.Record(FlakyGroup{Key: group.Key, Examples: []RspecExample{{Id: "./spec/flaky_spec.rb[1:1]"}, {Id: "./spec/flaky_spec.rb[1:2]"}}}, Build{Branch: "feature"}, first.Add(time.Hour))
+	summary.Record(group, Build{Branch: "main", Provider: ProviderCircleCI, ID: "job-1"}, first.Add(2*time.Hour))
+
+	assert.Equal(t, OccurrenceSummary{
+		Count:     3,
+		FirstSeen: first,
+		LastSeen:  first.Add(2 * time.Hour),
+		Examples:  []string{"./spec/flaky_spec.rb[1:1]", "./spec/flaky_spec.rb[1:2]"},
+		Branches:  []string{"main", "feature"},
+		LastBuild: "circleci/job-1",
+	}, summary)
+}
+
+func TestReplaceMarkdownSummary(t *testing.T) {
+	summary := OccurrenceSummary{}
+	summary.Record(FlakyGroup{Examples: []RspecExample{{Id: "./spec/flaky_spec.rb[1:1]"}}}, Build{}, time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC))
+
+	body := ReplaceMarkdownSummary("report\n\n<!-- rspec-sanity:fingerprint=abc -->", &summary)
+	assert.Contains(t, body, "- Occurrences: 1\n")
+	assert.Contains(t, body, "- Examples: `./spec/flaky_spec.rb[1:1]`\n")
+	assert.True(t, strings.HasSuffix(body, "report\n\n<!-- rspec-sanity:fingerprint=abc -->"))
+
+	parsed := ParseMarkdownSummary(body)
+	assert.Equal(t, summary, parsed)
+
+	parsed.Record(FlakyGroup{}, Build{}, time.Date(2026, 10, 2, 8, 0, 0, 0, time.UTC))
+	updated := ReplaceMarkdownSummary(body, &parsed)
+	assert.Contains(t, updated, "- Occurrences: 2\n")
+	assert.NotContains(t, updated, "- Occurrences: 1\n")
+	assert.Equal(t, 2, ParseMarkdownSummary(updated).Count)
+
+	assert.Equal(t, OccurrenceSummary{}, ParseMarkdownSummary("no summary"))
+}
+
+func TestReplaceJiraSummary(t *testing.T) {
+	summary := OccurrenceSummary{Count: 1, Examples: []string{"./spec/flaky_spec.rb[1:1]"}}
+
+	description := ReplaceJiraWikiSummary("report", &summary)
+	assert.Contains(t, description, "{panel:title=rspec-sanity summary}\n* Occurrences: 1\n")
+
+	summary.Count = 2
+	description = ReplaceJiraWikiSummary(description, &summary)
+	assert.Contains(t, description, "* Occurrences: 2\n")
+	assert.NotContains(t, description, "* Occurrences: 1\n")
+	assert.True(t, strings.HasSuffix(description, "\n\nreport"))
+
+	doc := ReplaceADFSummary(MarkdownToADF("report"), &summary)
+	assert.Equal(t, []string{"panel", "paragraph"}, adfTypes(doc.Content))
+
+	summary.Count = 3
+	doc = ReplaceADFSummary(doc, &summary)
+	assert.Equal(t, []string{"panel", "paragraph"}, adfTypes(doc.Content))
+	assert.Equal(t, "Occurrences: 3", doc.Content[0].Content[1].Content[0].Content[0].Content[0].Text)
+}
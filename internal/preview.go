@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"log"
+)
+
+// Preview is a rendered issue title and body of a single reporter
+type Preview struct {
+	Reporter string
+	Group    FlakyGroup
+	Title    string
+	Body     string
+}
+
+// PreviewGroups returns groups to render previews for - examples which failed
+// according to the persistence file or (when requested or nothing failed)
+// sample data
+func (c *Config) PreviewGroups(fromPersistenceFile bool) ([]FlakyGroup, error) {
+	if !fromPersistenceFile {
+		return []FlakyGroup{verificationGroup}, nil
+	}
+
+	examples, err := c.CollectExamples()
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []RspecExample
+	for _, example := range examples {
+		if example.Failed() {
+			failed = append(failed, example)
+		}
+	}
+
+	if len(failed) == 0 {
+		log.Println("[preview] No failed examples in persistence file, using sample data")
+		return []FlakyGroup{verificationGroup}, nil
+	}
+
+	return c.GroupFlakies(failed)
+}
+
+func RenderPreviews(config *Config, groups []FlakyGroup) ([]Preview, error) {
+	type source struct {
+		name  string
+		title *Template
+		body  *Template
+	}
+
+	var sources []source
+
+	if config.Github != nil {
+		sources = append(sources, source{"github", config.Github.GetTitleTemplate(), config.Github.GetTemplate()})
+	}
+
+	if config.Jira != nil {
+		sources = append(sources, source{"jira", config.Jira.GetTitleTemplate(), config.Jira.GetTemplate()})
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no reporter with templates configured (github or jira)")
+	}
+
+	var previews []Preview
+
+	for _, src := range sources {
+		for _, group := range groups {
+			title, err := RenderTitle(src.title, group)
+			if err != nil {
+				return nil, err
+			}
+
+			body, err := ExecuteTemplate(src.body, group)
+			if err != nil {
+				return nil, err
+			}
+
+			previews = append(previews, Preview{
+				Reporter: src.name,
+				Group:    group,
+				Title:    title,
+				Body:     body,
+			})
+		}
+	}
+
+	return previews, nil
+}
+
+func WritePreviews(w io.Writer, previews []Preview, renderMarkdown bool) {
+	for _, preview := range previews {
+		fmt.Fprintf(w, "===== [%s] %s =====\n", preview.Reporter, preview.Group.Key)
+		fmt.Fprintf(w, "Title: %s\n\n", preview.Title)
+
+		body := preview.Body
+		if renderMarkdown && preview.Reporter == "github" {
+			body = RenderMarkdown(body)
+		}
+
+		fmt.Fprintln(w, body)
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderPreviews(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	persistencePath := filepath.Join(dir, "examples.txt")
+
+	t.Setenv("RSPEC_SANITY_GITHUB_TOKEN", "")
+	os.Unsetenv("RSPEC_SANITY_GITHUB_TOKEN")
+
+	data := `
+command = "bundle exec rspec"
+persistence_file = "` + persistencePath + `"
+
+[github]
+owner = "jdoe"
+repo = "rspec-sanity"
+title_template = "[flaky] {{ .GroupKey }}"
+template = '''
+| Example |
+| --- |
+{{- range .Examples }}
+| ` + "`{{ .Id }}`" + ` |
+{{- end }}'''
+`
+	assert.NoError(t, os.WriteFile(configPath, []byte(data), 0644))
+
+	_, err := LoadConfig(configPath)
+	assert.EqualError(t, err, "specify github token under RSPEC_SANITY_GITHUB_TOKEN env")
+
+	config, err := LoadConfigWithoutCredentials(configPath)
+	assert.NoError(t, err)
+
+	examples := `example_id                  | status | run_time        |
+--------------------------- | ------ | --------------- |
+./spec/flaky_spec.rb[1:1]   | failed | 0.00051 seconds |
+./spec/stable_spec.rb[1:1]  | passed | 0.00005 seconds |
+`
+	assert.NoError(t, os.WriteFile(persistencePath, []byte(examples), 0644))
+
+	groups, err := config.PreviewGroups(true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(groups))
+	assert.Equal(t, "./spec/flaky_spec.rb", groups[0].Key)
+
+	previews, err := RenderPreviews(config, groups)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(previews))
+	assert.Equal(t, "[flaky] ./spec/flaky_spec.rb", previews[0].Title)
+	assert.Equal(t, "| Example |\n| --- |\n| `./spec/flaky_spec.rb[1:1]` |", previews[0].Body)
+
+	var out bytes.Buffer
+	WritePreviews(&out, previews, true)
+	assert.Contains(t, out.String(), "===== [github] ./spec/flaky_spec.rb =====\nTitle: [flaky] ./spec/flaky_spec.rb\n")
+	assert.Contains(t, out.String(), "\033[36m./spec/flaky_spec.rb[1:1]\033[0m")
+
+	groups, err = config.PreviewGroups(false)
+	assert.NoError(t, err)
+	assert.Equal(t, verificationGroup.Key, groups[0].Key)
+}
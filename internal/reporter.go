@@ -1,23 +1,92 @@
 package internal
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
 type Reporter interface {
 	Init() error
-	ReportFlaky([]RspecExample) error
-	Verify() error
+	ReportFlaky(FlakyGroup) error
+	Verify(VerifyOptions) error
+}
+
+// Finalizer is implemented by reporters that need to flush their state once
+// every flaky group has been reported.
+type Finalizer interface {
+	Finalize(result *RunnerResult) error
+}
+
+type ReportResult struct {
+	Group FlakyGroup
+	Error error
 }
 
-func ReportFlakies(reporter Reporter, flakies []RspecExample) error {
-	groups := make(map[string][]RspecExample)
-	for _, example := range flakies {
-		groups[example.Filename()] = append(groups[example.Filename()], example)
+func (rr *ReportResult) Succeeded() bool {
+	return rr.Error == nil
+}
+
+// ReportFlakies reports groups ordered by their key using up to `concurrency`
+// workers. Every group is attempted - errors are collected and returned
+// joined, together with per-group results (in the same order).
+func ReportFlakies(reporter Reporter, groups []FlakyGroup, concurrency int) ([]ReportResult, error) {
+	sorted := make([]FlakyGroup, len(groups))
+	copy(sorted, groups)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	if concurrency < 1 {
+		concurrency = 1
 	}
 
-	for _, group := range groups {
-		err := reporter.ReportFlaky(group)
-		if err != nil {
-			return err
+	results := make([]ReportResult, len(sorted))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = ReportResult{
+					Group: sorted[idx],
+					Error: reporter.ReportFlaky(sorted[idx]),
+				}
+			}
+		}()
+	}
+
+	for idx := range sorted {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if !result.Succeeded() {
+			errs = append(errs, fmt.Errorf(`failed to report "%s": %w`, result.Group.Key, result.Error))
 		}
 	}
 
-	return nil
+	return results, errors.Join(errs...)
+}
+
+// IsFinalizer tells whether the reporter has to be finalized even when there
+// are no flaky groups to report
+func IsFinalizer(reporter Reporter) bool {
+	_, ok := reporter.(Finalizer)
+	return ok
+}
+
+func FinalizeReporter(reporter Reporter, result *RunnerResult) error {
+	finalizer, ok := reporter.(Finalizer)
+	if !ok {
+		return nil
+	}
+
+	return finalizer.Finalize(result)
 }
@@ -1,13 +1,17 @@
 package internal
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 type MockReporter struct {
+	mu     sync.Mutex
 	Groups map[string][]RspecExample
+	Fail   map[string]bool
 }
 
 func (m *MockReporter) Init() error {
@@ -15,13 +19,19 @@ func (m *MockReporter) Init() error {
 	return nil
 }
 
-func (m *MockReporter) ReportFlaky(flakies []RspecExample) error {
-	filename := flakies[0].Filename()
-	m.Groups[filename] = append(m.Groups[filename], flakies...)
+func (m *MockReporter) ReportFlaky(group FlakyGroup) error {
+	if m.Fail[group.Key] {
+		return fmt.Errorf("tracker unavailable")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Groups[group.Key] = append(m.Groups[group.Key], group.Examples...)
 	return nil
 }
 
-func (m *MockReporter) Verify() error {
+func (m *MockReporter) Verify(opts VerifyOptions) error {
 	return nil
 }
 
@@ -30,13 +40,16 @@ func TestReportFlakies(t *testing.T) {
 	err := reporter.Init()
 	assert.NoError(t, err)
 
-	err = ReportFlakies(reporter, []RspecExample{
+	groups, err := GroupFlakies([]RspecExample{
 		{Id: "./spec/flaky_spec.rb[1:1]"},
 		{Id: "./spec/flaky_spec.rb[1:2]"},
 		{Id: "./spec/flaky_spec.rb[1:3]"},
 		{Id: "./spec/new_flaky_spec.rb[1:1]"},
-	})
- 
+	}, GroupByFile, &CodeOwners{})
+	assert.NoError(t, err)
+
+	_, err = ReportFlakies(reporter, groups, 1)
+
 	assert.NoError(t, err)
 
 	assert.Equal(t, 2, len(reporter.Groups))
@@ -47,3 +60,28 @@ func TestReportFlakies(t *testing.T) {
 	assert.Equal(t, 1, len(reporter.Groups["./spec/new_flaky_spec.rb"]))
 	assert.Equal(t, "./spec/new_flaky_spec.rb[1:1]", reporter.Groups["./spec/new_flaky_spec.rb"][0].Id)
 }
+
+func TestReportFlakiesCollectsErrors(t *testing.T) {
+	reporter := &MockReporter{
+		Fail: map[string]bool{"b": true, "d": true},
+	}
+	err := reporter.Init()
+	assert.NoError(t, err)
+
+	groups := []FlakyGroup{{Key: "d"}, {Key: "c"}, {Key: "b"}, {Key: "a"}}
+
+	results, err := ReportFlakies(reporter, groups, 3)
+	assert.Error(t, err)
+	assert.Equal(t, "failed to report \"b\": tracker unavailable\nfailed to report \"d\": tracker unavailable", err.Error())
+
+	assert.Equal(t, 4, len(results))
+	assert.Equal(t, "a", results[0].Group.Key)
+	assert.True(t, results[0].Succeeded())
+	assert.Equal(t, "b", results[1].Group.Key)
+	assert.False(t, results[1].Succeeded())
+	assert.True(t, results[2].Succeeded())
+	assert.False(t, results[3].Succeeded())
+
+	assert.Equal(t, 2, len(reporter.Groups))
+	assert.Equal(t, "d", groups[0].Key)
+}
@@ -0,0 +1,233 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryTransport retries requests failing with network errors, rate limits
+// or transient server errors with an exponential backoff, honouring
+// Retry-After and X-RateLimit-Reset headers. All requests share the optional
+// deadline (see report_timeout).
+type RetryTransport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxWait is the longest delay requested by the server worth waiting for
+	MaxWait  time.Duration
+	Deadline time.Time
+
+	sleep func(ctx context.Context, d time.Duration) error
+	now   func() time.Time
+}
+
+func NewRetryTransport(timeout time.Duration) *RetryTransport {
+	transport := &RetryTransport{
+		Base:       http.DefaultTransport,
+		MaxRetries: 4,
+		MinBackoff: time.Second,
+		MaxBackoff: 30 * time.Second,
+		MaxWait:    2 * time.Minute,
+	}
+
+	if timeout > 0 {
+		transport.Deadline = time.Now().Add(timeout)
+	}
+
+	return transport
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := t.withDeadline(req.Context())
+	req = req.WithContext(ctx)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base().RoundTrip(req)
+
+		delay, retry := t.retryDelay(req, resp, err, attempt)
+		if retry && req.Body != nil && req.GetBody == nil {
+			retry = false
+		}
+
+		if !retry {
+			if resp != nil {
+				resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			} else {
+				cancel()
+			}
+			return resp, err
+		}
+
+		reason := "network error"
+		if resp != nil {
+			reason = resp.Status
+			drainBody(resp)
+		}
+		log.Printf("[http] Retrying %s %s in %s (attempt %d/%d): %s", req.Method, req.URL.Path, delay, attempt+1, t.MaxRetries, reason)
+
+		if err := t.doSleep(ctx, delay); err != nil {
+			cancel()
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// retryDelay tells whether and after what delay the request should be retried;
+// requests which may have been processed already (network and gateway errors)
+// are retried only when repeating them is safe, rate limited ones always
+func (t *RetryTransport) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= t.MaxRetries {
+		return 0, false
+	}
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || !idempotent(req.Method) {
+			return 0, false
+		}
+
+		delay := t.backoff(attempt)
+		return delay, !t.exceedsDeadline(delay)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusBadGateway, resp.StatusCode == http.StatusServiceUnavailable, resp.StatusCode == http.StatusGatewayTimeout:
+		// eg. a created issue could be duplicated
+		if !idempotent(req.Method) {
+			return 0, false
+		}
+	// Github signals (secondary) rate limits with 403
+	case resp.StatusCode == http.StatusForbidden && (resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"):
+	default:
+		return 0, false
+	}
+
+	delay, ok := t.serverDelay(resp)
+	if !ok {
+		delay = t.backoff(attempt)
+	}
+
+	if delay > t.MaxWait {
+		return 0, false
+	}
+
+	return delay, !t.exceedsDeadline(delay)
+}
+
+// serverDelay reads the delay requested by the server
+func (t *RetryTransport) serverDelay(resp *http.Response) (time.Duration, bool) {
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return maxDuration(date.Sub(t.clock()), 0), true
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return maxDuration(time.Unix(reset, 0).Sub(t.clock()), 0) + time.Second, true
+		}
+	}
+
+	return 0, false
+}
+
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	delay := t.MinBackoff << attempt
+	if delay > t.MaxBackoff || delay <= 0 {
+		return t.MaxBackoff
+	}
+	return delay
+}
+
+func (t *RetryTransport) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if t.Deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, t.Deadline)
+}
+
+func (t *RetryTransport) exceedsDeadline(delay time.Duration) bool {
+	return !t.Deadline.IsZero() && t.clock().Add(delay).After(t.Deadline)
+}
+
+func (t *RetryTransport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+func (t *RetryTransport) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+func (t *RetryTransport) doSleep(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// drainBody lets the connection be reused
+func drainBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// cancelOnClose releases the request context once the response is read
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetryTransport(delays *[]time.Duration) *RetryTransport {
+	transport := NewRetryTransport(0)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return nil
+	}
+	return transport
+}
+
+func TestRetryTransport(t *testing.T) {
+	var bodies []string
+	responses := []func(w http.ResponseWriter){
+		func(w http.ResponseWriter) { w.WriteHeader(http.StatusTooManyRequests) },
+		func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusForbidden)
+		},
+		func(w http.ResponseWriter) { w.WriteHeader(http.StatusCreated) },
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		responses[len(bodies)-1](w)
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	client := &http.Client{Transport: testRetryTransport(&delays)}
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"body":"flaky"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, []string{`{"body":"flaky"}`, `{"body":"flaky"}`, `{"body":"flaky"}`}, bodies)
+	assert.Equal(t, []time.Duration{time.Second, 7 * time.Second}, delays)
+}
+
+func TestRetryTransportNonIdempotent(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	client := &http.Client{Transport: testRetryTransport(&delays)}
+
+	// the issue could have been created before the proxy timed out
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"title":"flaky"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, 1, requests)
+
+	requests = 0
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(`{"value":1}`))
+	assert.NoError(t, err)
+
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, requests)
+}
+
+func TestRetryTransportGivesUp(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	client := &http.Client{Transport: testRetryTransport(&delays)}
+
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 5, requests)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}, delays)
+
+	// plain 403 is not a rate limit
+	requests = 0
+	resp, err = client.Get(server.URL + "/forbidden")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, requests)
+}
+
+func TestRetryTransportServerDelay(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	transport := NewRetryTransport(0)
+	transport.now = func() time.Time { return now }
+
+	req := httptest.NewRequest(http.MethodPost, "/repos/jdoe/repo/issues", nil)
+	resp := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}
+	resp.Header.Set("X-RateLimit-Remaining", "0")
+	resp.Header.Set("X-RateLimit-Reset", "1792411230") // 30s after now
+
+	delay, retry := transport.retryDelay(req, resp, nil, 0)
+	assert.True(t, retry)
+	assert.Equal(t, 31*time.Second, delay)
+
+	// waiting past the deadline is pointless
+	transport.Deadline = now.Add(10 * time.Second)
+	_, retry = transport.retryDelay(req, resp, nil, 0)
+	assert.False(t, retry)
+
+	// too long to wait for
+	transport.Deadline = time.Time{}
+	resp.Header.Set("X-RateLimit-Reset", "1792415000")
+	_, retry = transport.retryDelay(req, resp, nil, 0)
+	assert.False(t, retry)
+}
@@ -1,12 +1,15 @@
 package internal
 
 import (
+	"strconv"
 	"strings"
+	"time"
 )
 
 type RspecExample struct {
-	Id string
-	Status string
+	Id      string        `json:"id"`
+	Status  string        `json:"status"`
+	RunTime time.Duration `json:"run_time,omitempty"`
 }
 
 func (r *RspecExample) Failed() bool {
@@ -21,13 +24,72 @@ func (r *RspecExample) Filename() string {
 	return strings.Split(r.Id, "[")[0]
 }
 
+// Line returns the line number when the example id is location based
+// (eg. "./spec/foo_spec.rb:12"), 0 otherwise - persistence file ids
+// (eg. "./spec/foo_spec.rb[1:2]") don't carry line information.
+func (r *RspecExample) Line() int {
+	filename := r.Filename()
+	idx := strings.LastIndex(filename, ":")
+	if idx == -1 {
+		return 0
+	}
+
+	line, err := strconv.Atoi(filename[idx+1:])
+	if err != nil {
+		return 0
+	}
+
+	return line
+}
+
+// Path returns the spec file path without the leading "./" and line number.
+func (r *RspecExample) Path() string {
+	path := r.Filename()
+	if r.Line() > 0 {
+		path = path[:strings.LastIndex(path, ":")]
+	}
+
+	return strings.TrimPrefix(path, "./")
+}
+
 func ParseRspecExample(line string) RspecExample {
 	parts := strings.Split(line, "|")
 
-	return RspecExample{
-		Id: strings.TrimSpace(parts[0]),
+	example := RspecExample{
+		Id:     strings.TrimSpace(parts[0]),
 		Status: strings.TrimSpace(parts[1]),
 	}
+
+	if len(parts) > 2 {
+		example.RunTime = parseRunTime(parts[2])
+	}
+
+	return example
+}
+
+// parseRunTime parses durations formatted by rspec, eg. "0.00051 seconds"
+// or "1 minute 5.2 seconds"; returns 0 for unknown formats
+func parseRunTime(value string) time.Duration {
+	fields := strings.Fields(value)
+	var total float64
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		amount, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return 0
+		}
+
+		switch strings.TrimSuffix(fields[i+1], "s") {
+		case "minute":
+			total += amount * 60
+		case "second":
+			total += amount
+		default:
+			return 0
+		}
+	}
+
+	return time.Duration(total * float64(time.Second))
 }
 
 func FindFlakies(firstRun []RspecExample, secondRun []RspecExample) []RspecExample {
@@ -45,3 +107,19 @@ func FindFlakies(firstRun []RspecExample, secondRun []RspecExample) []RspecExamp
 
 	return flakies
 }
+
+func FindFailures(firstRun []RspecExample, secondRun []RspecExample) []RspecExample {
+	var failures []RspecExample
+
+	for _, example := range firstRun {
+		if example.Failed() {
+			for _, second_example := range secondRun {
+				if example.Id == second_example.Id && second_example.Failed() {
+					failures = append(failures, example)
+				}
+			}
+		}
+	}
+
+	return failures
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestParseRspecExample(t *testing.T) {
@@ -10,6 +11,10 @@ func TestParseRspecExample(t *testing.T) {
 
 	assert.Equal(t, example.Id, "./spec/flaky_spec.rb[1:1]")
 	assert.Equal(t, example.Status, "passed")
+	assert.Equal(t, 290*time.Microsecond, example.RunTime)
+
+	example = ParseRspecExample("./spec/slow_spec.rb[1:1]        | failed | 1 minute 2.5 seconds |")
+	assert.Equal(t, 62500*time.Millisecond, example.RunTime)
 }
 
 func TestFindFlakies(t *testing.T) {
@@ -41,3 +46,34 @@ func TestRspecExampleFilename(t *testing.T) {
 	example := RspecExample{Id: "./spec/some_spec.rb[2:2]"}
 	assert.Equal(t, example.Filename(), "./spec/some_spec.rb")
 }
+
+func TestFindFailures(t *testing.T) {
+	firstRun := []RspecExample{
+		{Id: "./spec/some_other_spec.rb[1:1]", Status: "failed"},
+		{Id: "./spec/some_spec.rb[2:2]", Status: "failed"},
+		{Id: "./spec/test_spec.rb[1:3]", Status: "passed"},
+	}
+
+	secondRun := []RspecExample{
+		{Id: "./spec/some_other_spec.rb[1:1]", Status: "failed"},
+		{Id: "./spec/some_spec.rb[2:2]", Status: "passed"},
+		{Id: "./spec/test_spec.rb[1:3]", Status: "passed"},
+	}
+
+	expected := []RspecExample{
+		{Id: "./spec/some_other_spec.rb[1:1]", Status: "failed"},
+	}
+
+	assert.Equal(t, expected, FindFailures(firstRun, secondRun))
+	assert.Empty(t, FindFailures(firstRun[1:], secondRun))
+}
+
+func TestRspecExampleLocation(t *testing.T) {
+	example := RspecExample{Id: "./spec/some_spec.rb[2:2]"}
+	assert.Equal(t, 0, example.Line())
+	assert.Equal(t, "spec/some_spec.rb", example.Path())
+
+	example = RspecExample{Id: "./spec/some_spec.rb:42"}
+	assert.Equal(t, 42, example.Line())
+	assert.Equal(t, "spec/some_spec.rb", example.Path())
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -15,9 +16,11 @@ type Runner struct {
 }
 
 type RunnerResult struct {
-	StatusCode    int
-	Error         error
-	FlakyExamples []RspecExample
+	StatusCode     int
+	Error          error
+	Attempts       int
+	FlakyExamples  []RspecExample
+	FailedExamples []RspecExample
 }
 
 func (rr *RunnerResult) HasFlakies() bool {
@@ -33,12 +36,14 @@ func (r *Runner) Run() RunnerResult {
 		return RunnerResult{
 			StatusCode: status,
 			Error:      err,
+			Attempts:   1,
 		}
 	} else if r.Settings.SkipRerun {
 		log.Printf("[rspec-sanity] Build failed with %v, but skipping rerun", err)
 		return RunnerResult{
 			StatusCode: status,
 			Error:      err,
+			Attempts:   1,
 		}
 	} else {
 		log.Println("[rspec-sanity] Build failed, rerunning failed tests")
@@ -49,26 +54,42 @@ func (r *Runner) Run() RunnerResult {
 			return RunnerResult{
 				StatusCode: status,
 				Error:      err,
+				Attempts:   1,
 			}
 		}
 
 		command = r.Settings.Config.RerunCommand(r.Settings.Pattern)
 		status, err = r.exec(command, 2)
 
-		if err != nil {
+		// examples still failing make the rerun exit non-zero, which is a
+		// regular result - only bail out when rspec couldn't be run at all
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
 			return RunnerResult{
 				StatusCode: status,
 				Error:      err,
+				Attempts:   2,
+			}
+		}
+
+		examplesSecondRun, collectErr := r.Settings.Config.CollectExamples()
+		if collectErr != nil {
+			return RunnerResult{
+				StatusCode: status,
+				Error:      collectErr,
+				Attempts:   2,
 			}
 		}
 
-		examplesSecondRun, err := r.Settings.Config.CollectExamples()
 		flakies := FindFlakies(examplesFirstRun, examplesSecondRun)
+		failures := FindFailures(examplesFirstRun, examplesSecondRun)
 
 		return RunnerResult{
-			StatusCode: status,
-			Error:      err,
-			FlakyExamples: flakies,
+			StatusCode:     status,
+			Error:          err,
+			Attempts:       2,
+			FlakyExamples:  flakies,
+			FailedExamples: failures,
 		}
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,9 +48,9 @@ exit 0
 		Settings: &Settings{
 			Config: Config{
 				PersistenceFile: tempFile.Name(),
-				Command: fmt.Sprintf("/bin/bash %s", scriptFile.Name()),
-				Arguments: "1",
-				RerunArguments: "0",
+				Command:         fmt.Sprintf("/bin/bash %s", scriptFile.Name()),
+				Arguments:       "1",
+				RerunArguments:  "0",
 			},
 		},
 	}
@@ -64,3 +65,40 @@ exit 0
 	assert.Error(t, &exec.ExitError{}, result.Error)
 	assert.Equal(t, 1, result.StatusCode)
 }
+
+func TestRunnerSecondRunWithFailures(t *testing.T) {
+	persistenceFile := filepath.Join(t.TempDir(), "examples.txt")
+
+	scriptFile, err := os.CreateTemp("", "script")
+	assert.NoError(t, err)
+	defer os.Remove(scriptFile.Name())
+
+	data := fmt.Sprintf(`#!/bin/bash
+if [ "$RSPEC_SANITY_ATTEMPT" == "1" ]; then
+	printf 'example_id | status |\n---------- | ------ |\n./spec/a_spec.rb[1:1] | failed |\n./spec/b_spec.rb[1:1] | failed |\n./spec/c_spec.rb[1:1] | passed |\n' > %[1]s
+else
+	printf 'example_id | status |\n---------- | ------ |\n./spec/a_spec.rb[1:1] | passed |\n./spec/b_spec.rb[1:1] | failed |\n./spec/c_spec.rb[1:1] | passed |\n' > %[1]s
+fi
+
+exit 1
+`, persistenceFile)
+	_, err = scriptFile.Write([]byte(data))
+	assert.NoError(t, err)
+
+	runner := &Runner{
+		Settings: &Settings{
+			Config: Config{
+				PersistenceFile: persistenceFile,
+				Command:         fmt.Sprintf("/bin/bash %s", scriptFile.Name()),
+			},
+		},
+	}
+
+	result := runner.Run()
+
+	assert.Error(t, result.Error)
+	assert.Equal(t, 1, result.StatusCode)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Equal(t, []RspecExample{{Id: "./spec/a_spec.rb[1:1]", Status: "failed"}}, result.FlakyExamples)
+	assert.Equal(t, []RspecExample{{Id: "./spec/b_spec.rb[1:1]", Status: "failed"}}, result.FailedExamples)
+}
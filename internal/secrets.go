@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+// EnvPolicy controls which env variables are exposed to templates under .Env.
+// Both lists accept glob patterns (eg. "CIRCLE_*"); when allowlist is empty
+// every variable not matching the denylist is exposed. Variables that look
+// like secrets are always redacted.
+type EnvPolicy struct {
+	Allowlist []string
+	Denylist  []string
+}
+
+var secretNameRegexp = regexp.MustCompile(`(?i)(TOKEN|SECRET|PASSWORD|PASSWD|API_?KEY|PRIVATE_?KEY|CREDENTIAL)`)
+
+var secretValueRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{36,}`),
+	regexp.MustCompile(`github_pat_[A-Za-z0-9_]{22,}`),
+	regexp.MustCompile(`xox[abposr]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`(A3T[A-Z0-9]|AKIA|ASIA)[A-Z0-9]{16}`),
+	regexp.MustCompile(`ATATT[A-Za-z0-9_\-=]{20,}`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+}
+
+// secrets shorter than that are not scanned for in rendered output - it would
+// lead to redacting random words
+const minScannedSecretLength = 8
+
+func (p *EnvPolicy) Environment() map[string]string {
+	env := make(map[string]string)
+
+	for _, val := range os.Environ() {
+		pair := strings.SplitN(val, "=", 2)
+		name, value := pair[0], pair[1]
+
+		if len(p.Allowlist) > 0 && !matchesAny(p.Allowlist, name) {
+			continue
+		}
+
+		if matchesAny(p.Denylist, name) {
+			continue
+		}
+
+		if isSecret(name, value) {
+			value = redactedValue
+		}
+
+		env[name] = value
+	}
+
+	return env
+}
+
+// RedactSecrets replaces values of secret env variables and well known
+// token formats found in the rendered output, returning names of what was
+// redacted
+func RedactSecrets(output string) (string, []string) {
+	var found []string
+
+	for _, val := range os.Environ() {
+		pair := strings.SplitN(val, "=", 2)
+		name, value := pair[0], pair[1]
+
+		if len(value) < minScannedSecretLength || !isSecret(name, value) {
+			continue
+		}
+
+		if strings.Contains(output, value) {
+			output = strings.ReplaceAll(output, value, redactedValue)
+			found = append(found, name)
+		}
+	}
+
+	for _, re := range secretValueRegexps {
+		if re.MatchString(output) {
+			output = re.ReplaceAllString(output, redactedValue)
+			found = append(found, re.String())
+		}
+	}
+
+	return output, found
+}
+
+func redactOutput(name string, output string) string {
+	output, found := RedactSecrets(output)
+	if len(found) > 0 {
+		log.Printf("[rspec-sanity] Redacted secrets found in rendered %s: %s", name, strings.Join(found, ", "))
+	}
+
+	return output
+}
+
+func isSecret(name string, value string) bool {
+	if strings.HasPrefix(name, "RSPEC_SANITY_") && strings.HasSuffix(name, "_TOKEN") {
+		return true
+	}
+
+	if secretNameRegexp.MatchString(name) {
+		return true
+	}
+
+	for _, re := range secretValueRegexps {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -9,17 +9,25 @@ import (
 )
 
 type Settings struct {
-	SkipRerun  bool
-	ConfigPath string
-	Config     Config
-	Pattern    []string
+	SkipRerun       bool
+	SkipCredentials bool
+	ConfigPath      string
+	Config          Config
+	Pattern         []string
 }
 
 func (s *Settings) Load(cCtx *cli.Context) error {
 	pattern := cCtx.Args().Slice()
 	s.Pattern = pattern
 
-	config, err := LoadConfig(s.ConfigPath)
+	var config *Config
+	var err error
+
+	if s.SkipCredentials {
+		config, err = LoadConfigWithoutCredentials(s.ConfigPath)
+	} else {
+		config, err = LoadConfig(s.ConfigPath)
+	}
 
 	if err != nil {
 		return err
@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// SpooledReport is a flaky group that failed to be reported, saved as JSON
+// in the spool directory so it can be delivered later with `flush`
+type SpooledReport struct {
+	SpooledAt time.Time      `json:"spooled_at"`
+	Key       string         `json:"key"`
+	Examples  []RspecExample `json:"examples"`
+	Build     Build          `json:"build"`
+}
+
+func (r *SpooledReport) Group() FlakyGroup {
+	build := r.Build
+
+	return FlakyGroup{
+		Key:      r.Key,
+		Examples: r.Examples,
+		Build:    &build,
+	}
+}
+
+type Spool struct {
+	Dir string
+
+	now func() time.Time
+}
+
+func NewSpool(dir string) *Spool {
+	return &Spool{Dir: dir}
+}
+
+// Save writes the group to a new file of the spool; file names start with
+// the spooling time so reports are replayed in order
+func (s *Spool) Save(group FlakyGroup) (string, error) {
+	now := time.Now()
+	if s.now != nil {
+		now = s.now()
+	}
+
+	data, err := json.MarshalIndent(SpooledReport{
+		SpooledAt: now.UTC(),
+		Key:       group.Key,
+		Examples:  group.Examples,
+		Build:     group.DetectedBuild(),
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(s.Dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	pattern := fmt.Sprintf("%s-%s-*.json", now.UTC().Format("20060102T150405.000Z"), Fingerprint(group.Key))
+	file, err := os.CreateTemp(s.Dir, pattern)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.Write(append(data, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// SaveAll spools every group, errors of groups that couldn't be saved are
+// returned joined
+func (s *Spool) SaveAll(groups []FlakyGroup) error {
+	var errs []error
+
+	for _, group := range groups {
+		path, err := s.Save(group)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(`failed to spool "%s": %w`, group.Key, err))
+			continue
+		}
+
+		log.Printf("[spool] Saved %s to %s", group.Key, path)
+	}
+
+	return errors.Join(errs...)
+}
+
+// SaveFailed spools groups that failed to be reported because of transient
+// errors; groups failing permanently (eg. template errors, bad credentials)
+// would fail again on flush, so their errors are returned along with errors
+// of groups that couldn't be saved
+func (s *Spool) SaveFailed(results []ReportResult) error {
+	var failed []FlakyGroup
+	var errs []error
+
+	for _, result := range results {
+		if result.Succeeded() {
+			continue
+		}
+
+		if IsTransientError(result.Error) {
+			failed = append(failed, result.Group)
+		} else {
+			errs = append(errs, fmt.Errorf(`failed to report "%s": %w`, result.Group.Key, result.Error))
+		}
+	}
+
+	return errors.Join(append(errs, s.SaveAll(failed))...)
+}
+
+var jiraStatusCodeRegexp = regexp.MustCompile(`Status code: (\d+)`)
+
+// IsTransientError tells whether the request may succeed when repeated later:
+// network errors, timeouts, rate limits and server errors
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) {
+		return true
+	}
+
+	status := 0
+
+	var githubErr *github.ErrorResponse
+	if errors.As(err, &githubErr) && githubErr.Response != nil {
+		status = githubErr.Response.StatusCode
+	} else if match := jiraStatusCodeRegexp.FindStringSubmatch(err.Error()); match != nil {
+		// go-jira errors carry the status code in the message only
+		status, _ = strconv.Atoi(match[1])
+	}
+
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// List returns paths of spooled reports, oldest first
+func (s *Spool) List() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (s *Spool) Read(path string) (SpooledReport, error) {
+	var report SpooledReport
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+
+	err = json.Unmarshal(data, &report)
+	if err == nil && report.Key == "" {
+		err = fmt.Errorf("no group key")
+	}
+	if err != nil {
+		return report, fmt.Errorf(`invalid spooled report "%s": %w`, path, err)
+	}
+
+	return report, nil
+}
+
+// FlushSpool replays spooled reports in order; delivered reports are removed
+// from the spool, failed ones are kept for the next flush. Returns the number
+// of delivered reports and joined errors of the failed ones.
+func FlushSpool(reporter Reporter, spool *Spool) (int, error) {
+	// reports would be removed from the spool without being delivered anywhere
+	switch reporter.(type) {
+	case *NullReporter, *FileReporter:
+		return 0, fmt.Errorf("spooled reports can be flushed only to github or jira, none is configured")
+	}
+
+	paths, err := spool.List()
+	if err != nil {
+		return 0, err
+	}
+
+	flushed := 0
+	var errs []error
+
+	for _, path := range paths {
+		report, err := spool.Read(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		err = reporter.ReportFlaky(report.Group())
+		if err != nil {
+			log.Printf("[spool] Failed to flush %s: %v", report.Key, err)
+			errs = append(errs, fmt.Errorf(`failed to report "%s": %w`, report.Key, err))
+			continue
+		}
+
+		err = os.Remove(path)
+		if err != nil {
+			// reported already, the next flush would report it again
+			errs = append(errs, fmt.Errorf(`failed to remove flushed report "%s": %w`, path, err))
+		}
+
+		log.Printf("[spool] Flushed %s (spooled at %s)", report.Key, report.SpooledAt.Format(time.RFC3339))
+		flushed++
+	}
+
+	return flushed, errors.Join(errs...)
+}
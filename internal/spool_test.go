@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpoolSaveAndFlush(t *testing.T) {
+	clearBuildEnv(t)
+	t.Setenv("CIRCLECI", "true")
+	t.Setenv("CIRCLE_WORKFLOW_ID", "workflow-1")
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	spool := NewSpool(filepath.Join(t.TempDir(), "spool"))
+	spool.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	unavailable := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}}
+
+	err := spool.SaveFailed([]ReportResult{
+		{Group: FlakyGroup{Key: "b", Examples: []RspecExample{{Id: "b[1:1]"}}}, Error: unavailable},
+		{Group: FlakyGroup{Key: "c"}},
+		{Group: FlakyGroup{Key: "a", Examples: []RspecExample{{Id: "a[1:1]"}}}, Error: unavailable},
+		// would fail on flush again
+		{Group: FlakyGroup{Key: "d"}, Error: fmt.Errorf("template: report:1: unexpected EOF")},
+	})
+	assert.EqualError(t, err, `failed to report "d": template: report:1: unexpected EOF`)
+
+	paths, err := spool.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(paths))
+
+	report, err := spool.Read(paths[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "b", report.Key)
+	assert.Equal(t, "circleci/workflow-1", BuildKey(report.Build))
+
+	// replayed from another build
+	t.Setenv("CIRCLE_WORKFLOW_ID", "workflow-2")
+
+	reporter := &MockReporter{Fail: map[string]bool{"a": true}}
+	assert.NoError(t, reporter.Init())
+
+	flushed, err := FlushSpool(reporter, spool)
+	assert.Error(t, err)
+	assert.Equal(t, `failed to report "a": tracker unavailable`, err.Error())
+	assert.Equal(t, 1, flushed)
+	assert.Equal(t, []RspecExample{{Id: "b[1:1]"}}, reporter.Groups["b"])
+
+	paths, err = spool.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(paths))
+
+	reporter.Fail = nil
+	flushed, err = FlushSpool(reporter, spool)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, flushed)
+	assert.Equal(t, []RspecExample{{Id: "a[1:1]"}}, reporter.Groups["a"])
+
+	paths, err = spool.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(paths))
+}
+
+func TestSpooledReportGroup(t *testing.T) {
+	clearBuildEnv(t)
+	t.Setenv("CIRCLECI", "true")
+	t.Setenv("CIRCLE_WORKFLOW_ID", "workflow-2")
+
+	report := SpooledReport{Key: "a", Build: Build{Provider: "circleci", ID: "workflow-1"}}
+	group := report.Group()
+
+	assert.Equal(t, "circleci/workflow-1", BuildKey(group.DetectedBuild()))
+}
+
+func TestFlushSpoolKeepsInvalidReports(t *testing.T) {
+	spool := NewSpool(t.TempDir())
+	path := filepath.Join(spool.Dir, "invalid.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+
+	reporter := &MockReporter{}
+	assert.NoError(t, reporter.Init())
+
+	flushed, err := FlushSpool(reporter, spool)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid spooled report")
+	assert.Equal(t, 0, flushed)
+	assert.FileExists(t, path)
+}
+
+func TestFlushSpoolWithoutTracker(t *testing.T) {
+	spool := NewSpool(t.TempDir())
+	_, err := spool.Save(FlakyGroup{Key: "a"})
+	assert.NoError(t, err)
+
+	for _, reporter := range []Reporter{&NullReporter{}, NewFileReporter(&FileConfig{Path: "flaky.json"})} {
+		flushed, err := FlushSpool(reporter, spool)
+		assert.ErrorContains(t, err, "only to github or jira")
+		assert.Equal(t, 0, flushed)
+	}
+
+	paths, err := spool.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(paths))
+}
+
+func TestIsTransientError(t *testing.T) {
+	status := func(code int) error {
+		return &github.ErrorResponse{Response: &http.Response{StatusCode: code}}
+	}
+
+	assert.True(t, IsTransientError(&url.Error{Op: "Post", URL: "https://api.github.com", Err: errors.New("connection refused")}))
+	assert.True(t, IsTransientError(fmt.Errorf("wrapped: %w", status(http.StatusBadGateway))))
+	assert.True(t, IsTransientError(status(http.StatusTooManyRequests)))
+	assert.True(t, IsTransientError(&github.AbuseRateLimitError{}))
+	assert.True(t, IsTransientError(errors.New("request failed. Please analyze the request body for more details. Status code: 503")))
+
+	assert.False(t, IsTransientError(nil))
+	assert.False(t, IsTransientError(status(http.StatusUnauthorized)))
+	assert.False(t, IsTransientError(status(http.StatusNotFound)))
+	assert.False(t, IsTransientError(errors.New("request failed. Please analyze the request body for more details. Status code: 400")))
+	assert.False(t, IsTransientError(errors.New(`template: report:1: function "foo" not defined`)))
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// StaleCloser is implemented by reporters able to close tickets of flakies
+// that stopped occurring
+type StaleCloser interface {
+	CloseStale(opts StaleOptions) error
+}
+
+type StaleOptions struct {
+	Now    time.Time
+	MaxAge time.Duration
+	DryRun bool
+	// SpecFingerprints holds fingerprints of spec files present in the project;
+	// nil when groups don't map to files (see group_by)
+	SpecFingerprints map[string]bool
+	// directory spec files were looked up in
+	specRoot string
+}
+
+var fingerprintMarkerRegexp = regexp.MustCompile(`<!-- rspec-sanity:fingerprint=([0-9a-f]+) -->`)
+
+// directories never containing specs worth checking
+var staleSkippedDirs = map[string]bool{"node_modules": true, "vendor": true, "tmp": true, "log": true}
+
+func NewStaleOptions(config *Config, days int, dryRun bool) (StaleOptions, error) {
+	opts := StaleOptions{
+		Now:    time.Now(),
+		MaxAge: time.Duration(days) * 24 * time.Hour,
+		DryRun: dryRun,
+	}
+
+	if config.GroupBy != "" && config.GroupBy != GroupByFile {
+		return opts, nil
+	}
+
+	root := config.Root()
+	opts.SpecFingerprints = make(map[string]bool)
+	opts.specRoot = root
+
+	// group keys are relative to the project root, where rspec runs
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || staleSkippedDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if strings.HasSuffix(path, "_spec.rb") {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			opts.SpecFingerprints[Fingerprint(filepath.ToSlash(rel))] = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return opts, err
+	}
+
+	// otherwise every ticket would be closed as removed
+	if len(opts.SpecFingerprints) == 0 {
+		return opts, fmt.Errorf("no spec files (*_spec.rb) found in %s", root)
+	}
+
+	return opts, nil
+}
+
+// MatchSpecFiles stops closing tickets as removed when none of the tickets'
+// fingerprints matches a spec file - specs were most likely looked up in the
+// wrong directory (see project_root) rather than all removed
+func (o *StaleOptions) MatchSpecFiles(fingerprints []string) {
+	if o.SpecFingerprints == nil || len(fingerprints) == 0 {
+		return
+	}
+
+	for _, fingerprint := range fingerprints {
+		if o.SpecFingerprints[fingerprint] {
+			return
+		}
+	}
+
+	log.Printf("[rspec-sanity] None of %d ticket(s) match a spec file in %s, not closing tickets as removed", len(fingerprints), o.specRoot)
+	o.SpecFingerprints = nil
+}
+
+// StaleReason explains why the ticket should be closed, returns empty string
+// when it's not stale
+func (o *StaleOptions) StaleReason(fingerprint string, lastSeen time.Time) string {
+	if o.SpecFingerprints != nil && fingerprint != "" && !o.SpecFingerprints[fingerprint] {
+		return "the spec file no longer exists"
+	}
+
+	if o.MaxAge > 0 && o.Now.Sub(lastSeen) > o.MaxAge {
+		return fmt.Sprintf(
+			"no new occurrences since %s (%d days)",
+			lastSeen.Format("2006-01-02"),
+			int(o.Now.Sub(lastSeen).Hours()/24),
+		)
+	}
+
+	return ""
+}
+
+func staleComment(reason string) string {
+	return fmt.Sprintf("Closed by rspec-sanity: %s.", reason)
+}
+
+func CloseStale(reporter Reporter, opts StaleOptions) error {
+	closer, ok := reporter.(StaleCloser)
+	if !ok {
+		return fmt.Errorf("configured reporter doesn't support closing stale tickets")
+	}
+
+	return closer.CloseStale(opts)
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
+)
+
+func TestStaleReason(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	existing := Fingerprint("./spec/models/user_spec.rb")
+
+	opts := StaleOptions{
+		Now:              now,
+		MaxAge:           30 * 24 * time.Hour,
+		SpecFingerprints: map[string]bool{existing: true},
+	}
+
+	assert.Equal(t, "", opts.StaleReason(existing, now.Add(-24*time.Hour)))
+	assert.Equal(t, "no new occurrences since 2024-01-01 (60 days)", opts.StaleReason(existing, now.Add(-60*24*time.Hour)))
+	assert.Equal(t, "the spec file no longer exists", opts.StaleReason(Fingerprint("spec/removed_spec.rb"), now))
+
+	opts.SpecFingerprints = nil
+	assert.Equal(t, "", opts.StaleReason(Fingerprint("spec/removed_spec.rb"), now))
+}
+
+func TestStaleOptionsMatchSpecFiles(t *testing.T) {
+	existing := Fingerprint("spec/existing_spec.rb")
+	removed := Fingerprint("spec/removed_spec.rb")
+
+	opts := StaleOptions{SpecFingerprints: map[string]bool{existing: true}}
+	opts.MatchSpecFiles([]string{existing, removed})
+	assert.Equal(t, "the spec file no longer exists", opts.StaleReason(removed, time.Now()))
+
+	// specs looked up in the wrong directory
+	opts.SpecFingerprints = map[string]bool{Fingerprint("other/spec/existing_spec.rb"): true}
+	opts.MatchSpecFiles([]string{existing, removed})
+	assert.Nil(t, opts.SpecFingerprints)
+	assert.Equal(t, "", opts.StaleReason(removed, time.Now()))
+}
+
+func TestNewStaleOptions(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "spec", "models"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "node_modules", "spec"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "spec", "models", "user_spec.rb"), []byte(""), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "node_modules", "spec", "lib_spec.rb"), []byte(""), 0644))
+
+	// run from the project root, like rspec
+	t.Chdir(dir)
+
+	opts, err := NewStaleOptions(&Config{}, 30, true)
+	assert.NoError(t, err)
+	assert.True(t, opts.DryRun)
+	assert.Equal(t, 30*24*time.Hour, opts.MaxAge)
+	assert.Equal(t, map[string]bool{Fingerprint("./spec/models/user_spec.rb"): true}, opts.SpecFingerprints)
+
+	// run from elsewhere
+	t.Chdir(filepath.Join(dir, "spec"))
+
+	opts, err = NewStaleOptions(&Config{ProjectRoot: dir}, 30, true)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{Fingerprint("./spec/models/user_spec.rb"): true}, opts.SpecFingerprints)
+
+	// a checkout without specs would close every ticket as removed
+	_, err = NewStaleOptions(&Config{ProjectRoot: t.TempDir()}, 30, true)
+	assert.ErrorContains(t, err, "no spec files")
+
+	opts, err = NewStaleOptions(&Config{GroupBy: GroupByDirectory}, 30, false)
+	assert.NoError(t, err)
+	assert.Nil(t, opts.SpecFingerprints)
+}
+
+func TestGithubCloseStale(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	created := &github.Timestamp{Time: now.Add(-60 * 24 * time.Hour)}
+
+	var open []*github.Issue
+	for number := 1; number <= 3; number++ {
+		open = append(open, &github.Issue{
+			Number:    github.Int(number),
+			Body:      github.String("report\n\n" + FingerprintMarker(Fingerprint(fmt.Sprintf("spec/%d_spec.rb", number)))),
+			State:     github.String("open"),
+			CreatedAt: created,
+		})
+	}
+
+	var closed []int
+	mux := http.NewServeMux()
+	// one issue per page, closed issues drop out of the listing
+	mux.HandleFunc("GET /repos/jdoe/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+
+		var issues []*github.Issue
+		if page <= len(open) {
+			issues = open[page-1 : page]
+		}
+		if page < len(open) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, page+1))
+		}
+		json.NewEncoder(w).Encode(issues)
+	})
+	mux.HandleFunc("GET /repos/jdoe/repo/issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	})
+	mux.HandleFunc("POST /repos/jdoe/repo/issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("PATCH /repos/jdoe/repo/issues/{number}", func(w http.ResponseWriter, r *http.Request) {
+		number, _ := strconv.Atoi(r.PathValue("number"))
+		closed = append(closed, number)
+		open = slices.DeleteFunc(open, func(issue *github.Issue) bool { return issue.GetNumber() == number })
+		w.Write([]byte("{}"))
+	})
+
+	reporter := newTestGithubReporter(t, &GithubConfig{Owner: "jdoe", Repo: "repo"}, mux)
+
+	err := reporter.CloseStale(StaleOptions{Now: now, MaxAge: 30 * 24 * time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, closed)
+}
+
+func TestJiraFingerprint(t *testing.T) {
+	fingerprint := Fingerprint("./spec/flaky_spec.rb")
+
+	assert.Equal(t, "", jiraFingerprint(&jira.Issue{Fields: &jira.IssueFields{Labels: []string{"flaky-spec"}}}))
+	assert.Equal(t, fingerprint, jiraFingerprint(&jira.Issue{Fields: &jira.IssueFields{
+		Labels: []string{"flaky-spec", FingerprintLabel(fingerprint)},
+	}}))
+}
+
+func TestJiraCloseStale(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	created := jira.Time(now.Add(-60 * 24 * time.Hour))
+
+	var open []jira.Issue
+	for number := 1; number <= 3; number++ {
+		open = append(open, jira.Issue{
+			ID:  strconv.Itoa(number),
+			Key: fmt.Sprintf("PROD-%d", number),
+			Fields: &jira.IssueFields{
+				Labels:  []string{FingerprintLabel(Fingerprint(fmt.Sprintf("spec/%d_spec.rb", number)))},
+				Created: created,
+			},
+		})
+	}
+
+	var closed []string
+	mux := http.NewServeMux()
+	// one issue per page, closed issues drop out of the results
+	mux.HandleFunc("GET /rest/api/2/search", func(w http.ResponseWriter, r *http.Request) {
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+
+		issues := []jira.Issue{}
+		if startAt < len(open) {
+			issues = open[startAt : startAt+1]
+		}
+		json.NewEncoder(w).Encode(map[string]any{"startAt": startAt, "maxResults": 1, "total": len(open), "issues": issues})
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/{id}/comment", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("GET /rest/api/2/issue/{key}/transitions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"transitions": [{"id": "31", "name": "Done"}]}`))
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/{key}/transitions", func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("key")
+		closed = append(closed, key)
+		open = slices.DeleteFunc(open, func(issue jira.Issue) bool { return issue.Key == key })
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	reporter := newTestJiraReporter(t, &JiraConfig{ProjectId: "PROD", CloseTransition: "Done"}, mux)
+
+	err := reporter.CloseStale(StaleOptions{Now: now, MaxAge: 30 * 24 * time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PROD-1", "PROD-2", "PROD-3"}, closed)
+}
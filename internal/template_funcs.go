@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// templateFuncs are available in every reporter template. Functions taking
+// the value as the last argument are meant to be used in pipelines, eg.
+// {{ .GroupKey | truncate 50 }}
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"join":           templateJoin,
+		"truncate":       templateTruncate,
+		"default":        templateDefault,
+		"upper":          strings.ToUpper,
+		"markdownEscape": markdownEscape,
+		"jiraEscape":     jiraEscape,
+		"relpath":        templateRelpath,
+		"now":            func() time.Time { return time.Now().UTC() },
+		"duration":       templateDuration,
+		"githubBlobURL":  githubBlobURL,
+	}
+}
+
+// join accepts list of strings or examples (joined by their ids)
+func templateJoin(sep string, items any) (string, error) {
+	switch list := items.(type) {
+	case []string:
+		return strings.Join(list, sep), nil
+	case []RspecExample:
+		ids := make([]string, len(list))
+		for i, example := range list {
+			ids[i] = example.Id
+		}
+		return strings.Join(ids, sep), nil
+	case []any:
+		values := make([]string, len(list))
+		for i, value := range list {
+			values[i] = fmt.Sprint(value)
+		}
+		return strings.Join(values, sep), nil
+	default:
+		return "", fmt.Errorf("join: unsupported type %T", items)
+	}
+}
+
+func templateTruncate(length int, s string) string {
+	if utf8.RuneCountInString(s) <= length {
+		return s
+	}
+
+	if length < 1 {
+		return ""
+	}
+
+	runes := []rune(s)
+	return string(runes[:length-1]) + "…"
+}
+
+// default returns the fallback when the value is empty (zero value), eg.
+// {{ .Env.CIRCLE_BRANCH | default "unknown" }}
+func templateDefault(fallback any, value any) any {
+	if value == nil {
+		return fallback
+	}
+
+	v := reflect.ValueOf(value)
+	if v.IsZero() {
+		return fallback
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		if v.Len() == 0 {
+			return fallback
+		}
+	}
+
+	return value
+}
+
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "{", `\{`, "}", `\}`,
+	"[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "(", `\(`, ")", `\)`,
+	"#", `\#`, "+", `\+`, "!", `\!`, "|", `\|`, "~", `\~`,
+)
+
+func markdownEscape(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+// https://jira.atlassian.com/secure/WikiRendererHelpAction.jspa?section=all
+var jiraReplacer = strings.NewReplacer(
+	`\`, `\\`, "{", `\{`, "}", `\}`, "[", `\[`, "]", `\]`, "|", `\|`,
+	"*", `\*`, "_", `\_`, "+", `\+`, "^", `\^`, "~", `\~`, "!", `\!`,
+	"-", `\-`, "#", `\#`, "?", `\?`,
+)
+
+func jiraEscape(s string) string {
+	return jiraReplacer.Replace(s)
+}
+
+// relpath makes the path relative to the working directory (project root)
+func templateRelpath(path string) string {
+	if filepath.IsAbs(path) {
+		wd, err := os.Getwd()
+		if err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil {
+				path = rel
+			}
+		}
+	}
+
+	return strings.TrimPrefix(filepath.ToSlash(path), "./")
+}
+
+// duration formats time.Duration or number of seconds
+func templateDuration(value any) (string, error) {
+	var d time.Duration
+
+	switch v := value.(type) {
+	case time.Duration:
+		d = v
+	case float64:
+		d = time.Duration(v * float64(time.Second))
+	case int:
+		d = time.Duration(v) * time.Second
+	default:
+		return "", fmt.Errorf("duration: unsupported type %T", value)
+	}
+
+	switch {
+	case d >= time.Second:
+		d = d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		d = d.Round(time.Millisecond)
+	}
+
+	return d.String(), nil
+}
+
+// githubBlobURL links an example (or a path) to its source at the commit
+// being built, returns empty string when repository or commit is unknown
+func githubBlobURL(value any) (string, error) {
+	var path string
+	var line int
+
+	switch v := value.(type) {
+	case RspecExample:
+		path = v.Path()
+		line = v.Line()
+	case string:
+		path = templateRelpath(v)
+	default:
+		return "", fmt.Errorf("githubBlobURL: unsupported type %T", value)
+	}
+
+	repository := DetectRepository()
+	sha := DetectCommitSHA()
+
+	if repository == "" || sha == "" {
+		return "", nil
+	}
+
+	server := os.Getenv("GITHUB_SERVER_URL")
+	if server == "" {
+		server = "https://github.com"
+	}
+
+	url := fmt.Sprintf("%s/%s/blob/%s/%s", strings.TrimSuffix(server, "/"), repository, sha, path)
+	if line > 0 {
+		url = fmt.Sprintf("%s#L%d", url, line)
+	}
+
+	return url, nil
+}
@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Setenv("CIRCLE_BRANCH", "")
+	t.Setenv("RSPEC_SANITY_GITHUB_REPOSITORY", "jdoe/app")
+	t.Setenv("RSPEC_SANITY_COMMIT_SHA", "abc123")
+	t.Setenv("GITHUB_SERVER_URL", "")
+
+	group := FlakyGroup{
+		Key: "./spec/models/user_spec.rb",
+		Examples: []RspecExample{
+			{Id: "./spec/models/user_spec.rb[1:1]", RunTime: 1234567 * time.Microsecond},
+			{Id: "./spec/models/user_spec.rb:42"},
+		},
+	}
+
+	cases := map[string]string{
+		`{{ join ", " .Examples }}`:                    "./spec/models/user_spec.rb[1:1], ./spec/models/user_spec.rb:42",
+		`{{ .GroupKey | truncate 10 }}`:                "./spec/mo…",
+		`{{ .Env.CIRCLE_BRANCH | default "unknown" }}`: "unknown",
+		`{{ .GroupKey | relpath | upper }}`:            "SPEC/MODELS/USER_SPEC.RB",
+		`{{ markdownEscape "user_spec.rb[1:1]" }}`:     `user\_spec.rb\[1:1\]`,
+		`{{ jiraEscape "user_spec.rb[1:1]" }}`:         `user\_spec.rb\[1:1\]`,
+		`{{ (index .Examples 0).RunTime | duration }}`: "1.23s",
+		`{{ githubBlobURL (index .Examples 1) }}`:      "https://github.com/jdoe/app/blob/abc123/spec/models/user_spec.rb#L42",
+		`{{ githubBlobURL .GroupKey }}`:                "https://github.com/jdoe/app/blob/abc123/spec/models/user_spec.rb",
+		`{{ now.Year }}`:                               time.Now().UTC().Format("2006"),
+	}
+
+	for text, expected := range cases {
+		result, err := RenderTemplate(text, group)
+		assert.NoError(t, err, text)
+		assert.Equal(t, expected, result, text)
+	}
+}
@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const templatePartialExt = ".tmpl"
+
+type TemplateData struct {
+	GroupKey string
+	Examples []RspecExample
+	Build    Build
+	Env      map[string]string
+}
+
+// TemplateOptions are shared by every template loaded from the config
+type TemplateOptions struct {
+	Dir string
+	Env EnvPolicy
+}
+
+// Template is a parsed reporter template; rendered output is scanned for
+// leaked secrets before it is returned
+type Template struct {
+	tmpl *template.Template
+	env  EnvPolicy
+}
+
+// LoadTemplate parses inline template or, when templateFile is given, its
+// content. Every *.tmpl file from templates dir is available as a partial
+// named after the file (eg. "footer.tmpl" -> {{ template "footer" . }}).
+//
+// The template is executed against sample data so a broken template fails
+// at config load rather than mid-report.
+func LoadTemplate(name string, text string, templateFile string, opts TemplateOptions) (*Template, error) {
+	if templateFile != "" {
+		if text != "" {
+			return nil, fmt.Errorf("%s: specify either inline template or template file, not both", name)
+		}
+
+		content, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		text = string(content)
+	}
+
+	tmpl, err := ParseTemplate(name, text, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ExecuteTemplate(tmpl, verificationGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+func ParseTemplate(name string, text string, opts TemplateOptions) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs()).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Template{tmpl: tmpl, env: opts.Env}
+
+	if opts.Dir == "" {
+		return result, nil
+	}
+
+	partials, err := filepath.Glob(filepath.Join(opts.Dir, "*"+templatePartialExt))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, partial := range partials {
+		content, err := os.ReadFile(partial)
+		if err != nil {
+			return nil, err
+		}
+
+		partialName := strings.TrimSuffix(filepath.Base(partial), templatePartialExt)
+
+		_, err = tmpl.New(partialName).Parse(string(content))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func RenderTemplate(customTemplate string, group FlakyGroup) (string, error) {
+	tmpl, err := ParseTemplate("template", customTemplate, TemplateOptions{})
+
+	if err != nil {
+		return "", err
+	}
+
+	return ExecuteTemplate(tmpl, group)
+}
+
+func ExecuteTemplate(t *Template, group FlakyGroup) (string, error) {
+	if t == nil {
+		return "", fmt.Errorf("template not loaded")
+	}
+
+	var buf bytes.Buffer
+
+	data := TemplateData{
+		GroupKey: group.Key,
+		Examples: group.Examples,
+		Build:    group.DetectedBuild(),
+		Env:      t.env.Environment(),
+	}
+
+	err := t.tmpl.Execute(&buf, data)
+
+	if err != nil {
+		return "", err
+	}
+
+	return redactOutput(t.tmpl.Name(), buf.String()), nil
+}
+
+// RenderTitle renders the issue title - group key is used when no title
+// template is configured. Titles are always a single line.
+func RenderTitle(titleTemplate *Template, group FlakyGroup) (string, error) {
+	if titleTemplate == nil {
+		return group.Key, nil
+	}
+
+	title, err := ExecuteTemplate(titleTemplate, group)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(strings.Fields(title), " "), nil
+}
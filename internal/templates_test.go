@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTitle(t *testing.T) {
+	group := FlakyGroup{
+		Key:      "./spec/flaky_spec.rb",
+		Examples: []RspecExample{{Id: "./spec/flaky_spec.rb[1:1]"}},
+	}
+
+	title, err := RenderTitle(nil, group)
+	assert.NoError(t, err)
+	assert.Equal(t, "./spec/flaky_spec.rb", title)
+
+	tmpl, err := ParseTemplate("title", "[flaky] {{ .GroupKey }}\n({{ len .Examples }} examples)", TemplateOptions{})
+	assert.NoError(t, err)
+
+	title, err = RenderTitle(tmpl, group)
+	assert.NoError(t, err)
+	assert.Equal(t, "[flaky] ./spec/flaky_spec.rb (1 examples)", title)
+}
+
+func TestLoadTemplate(t *testing.T) {
+	dir := t.TempDir()
+	templatesDir := filepath.Join(dir, "partials")
+	assert.NoError(t, os.Mkdir(templatesDir, 0755))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(templatesDir, "footer.tmpl"), []byte("-- {{ .GroupKey }}"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(templatesDir, "ignored.txt"), []byte("{{ broken"), 0644))
+
+	templateFile := filepath.Join(dir, "body.tmpl")
+	assert.NoError(t, os.WriteFile(templateFile, []byte(`{{ len .Examples }} examples {{ template "footer" . }}`), 0644))
+
+	tmpl, err := LoadTemplate("github.template", "", templateFile, TemplateOptions{Dir: templatesDir})
+	assert.NoError(t, err)
+
+	body, err := ExecuteTemplate(tmpl, FlakyGroup{Key: "spec/a_spec.rb", Examples: []RspecExample{{Id: "spec/a_spec.rb[1:1]"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "1 examples -- spec/a_spec.rb", body)
+
+	_, err = LoadTemplate("github.template", "inline", templateFile, TemplateOptions{Dir: templatesDir})
+	assert.EqualError(t, err, "github.template: specify either inline template or template file, not both")
+
+	_, err = LoadTemplate("github.template", "{{ .Examples", "", TemplateOptions{})
+	assert.Error(t, err)
+
+	// unknown partials are only detected on execution
+	_, err = LoadTemplate("github.template", `{{ template "header" . }}`, "", TemplateOptions{Dir: templatesDir})
+	assert.Error(t, err)
+}
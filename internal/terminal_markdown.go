@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ansiReset     = "\033[0m"
+	ansiBold      = "\033[1m"
+	ansiHeading   = "\033[1;4m"
+	ansiCode      = "\033[36m"
+	ansiSeparator = "\033[2m"
+)
+
+var markdownBoldRegexp = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+var markdownCodeRegexp = regexp.MustCompile("`([^`]+)`")
+var markdownTableSeparatorRegexp = regexp.MustCompile(`^\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*$`)
+
+// RenderMarkdown renders the subset of Markdown used in issue templates
+// (headings, emphasis, inline code, lists and tables) for the terminal
+func RenderMarkdown(markdown string) string {
+	var out []string
+	var table [][]string
+
+	flush := func() {
+		if len(table) > 0 {
+			out = append(out, renderTable(table)...)
+			table = nil
+		}
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "|") {
+			if !markdownTableSeparatorRegexp.MatchString(trimmed) {
+				table = append(table, tableCells(trimmed))
+			}
+			continue
+		}
+
+		flush()
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			out = append(out, ansiHeading+strings.TrimSpace(strings.TrimLeft(trimmed, "#"))+ansiReset)
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			out = append(out, "  • "+renderInline(trimmed[2:]))
+		case strings.HasPrefix(trimmed, "<!--") && strings.HasSuffix(trimmed, "-->"):
+			// hidden in rendered markdown as well
+		default:
+			out = append(out, renderInline(line))
+		}
+	}
+
+	flush()
+
+	return strings.Join(out, "\n")
+}
+
+func renderInline(s string) string {
+	s = markdownBoldRegexp.ReplaceAllString(s, ansiBold+"$1"+ansiReset)
+	s = markdownCodeRegexp.ReplaceAllString(s, ansiCode+"$1"+ansiReset)
+	return s
+}
+
+func visibleText(s string) string {
+	s = markdownBoldRegexp.ReplaceAllString(s, "$1")
+	return markdownCodeRegexp.ReplaceAllString(s, "$1")
+}
+
+func visibleLength(s string) int {
+	return utf8.RuneCountInString(visibleText(s))
+}
+
+func tableCells(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+
+	return cells
+}
+
+func renderTable(rows [][]string) []string {
+	var widths []int
+
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], visibleLength(cell))
+		}
+	}
+
+	var lines []string
+
+	for r, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			padding := strings.Repeat(" ", widths[i]-visibleLength(cell))
+			if r == 0 && len(rows) > 1 {
+				cells[i] = ansiBold + visibleText(cell) + ansiReset + padding
+			} else {
+				cells[i] = renderInline(cell) + padding
+			}
+		}
+
+		lines = append(lines, strings.Join(cells, ansiSeparator+" │ "+ansiReset))
+	}
+
+	return lines
+}
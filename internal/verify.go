@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"fmt"
+	"log"
+)
+
+type VerifyOptions struct {
+	// CreateIssue creates (and afterwards closes or deletes) a real test issue
+	CreateIssue bool
+}
+
+// Checklist runs verification checks printing the result of every check;
+// a failed check doesn't stop the following ones
+type Checklist struct {
+	prefix string
+	failed int
+}
+
+func NewChecklist(prefix string) *Checklist {
+	return &Checklist{prefix: prefix}
+}
+
+func (c *Checklist) Check(name string, check func() (string, error)) bool {
+	details, err := check()
+
+	if err != nil {
+		c.failed++
+		log.Printf("[%s] [FAIL] %s: %v", c.prefix, name, err)
+		return false
+	}
+
+	if details != "" {
+		log.Printf("[%s] [ OK ] %s: %s", c.prefix, name, details)
+	} else {
+		log.Printf("[%s] [ OK ] %s", c.prefix, name)
+	}
+
+	return true
+}
+
+func (c *Checklist) Err() error {
+	if c.failed > 0 {
+		return fmt.Errorf("%s verification failed: %d check(s) failed", c.prefix, c.failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecklist(t *testing.T) {
+	checklist := NewChecklist("test")
+
+	assert.True(t, checklist.Check("passing", func() (string, error) { return "details", nil }))
+	assert.NoError(t, checklist.Err())
+
+	assert.False(t, checklist.Check("failing", func() (string, error) { return "", fmt.Errorf("boom") }))
+	assert.True(t, checklist.Check("passing after failure", func() (string, error) { return "", nil }))
+	assert.EqualError(t, checklist.Err(), "test verification failed: 1 check(s) failed")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"log"
 	"os"
 
@@ -31,7 +32,80 @@ func main() {
 		Commands: []*cli.Command{
 			{
 				Name:  "verify",
-				Usage: "verify configuration - will try to add a test issue report to Jira/Github",
+				Usage: "verify configuration - checks credentials and access to Jira/Github without creating anything",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "create-issue",
+						Usage: "Also create a test issue (closed or deleted right away)",
+					},
+				},
+				Action: func(cCtx *cli.Context) error {
+					err := settings.Load(cCtx)
+					if err != nil {
+						return err
+					}
+
+					reporter := settings.Config.GetReporter()
+					err = reporter.Init()
+					if err != nil {
+						return err
+					}
+
+					return reporter.Verify(internal.VerifyOptions{
+						CreateIssue: cCtx.Bool("create-issue"),
+					})
+				},
+			},
+			{
+				Name:  "preview",
+				Usage: "render issue titles and bodies for configured reporters locally (nothing is sent)",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "persistence-file",
+						Usage: "Use failed examples from the persistence file instead of sample data",
+					},
+					&cli.BoolFlag{
+						Name:  "markdown",
+						Usage: "Render Markdown (Github templates) for the terminal",
+					},
+				},
+				Action: func(cCtx *cli.Context) error {
+					settings.SkipCredentials = true
+
+					err := settings.Load(cCtx)
+					if err != nil {
+						return err
+					}
+
+					groups, err := settings.Config.PreviewGroups(cCtx.Bool("persistence-file"))
+					if err != nil {
+						return err
+					}
+
+					previews, err := internal.RenderPreviews(&settings.Config, groups)
+					if err != nil {
+						return err
+					}
+
+					internal.WritePreviews(os.Stdout, previews, cCtx.Bool("markdown"))
+					return nil
+				},
+			},
+			{
+				Name:    "close-stale",
+				Aliases: []string{"gc"},
+				Usage:   "close tickets of flaky tests that stopped occurring or whose spec file was removed",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "days",
+						Value: 30,
+						Usage: "Close tickets without new occurrences for `N` days",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Only print tickets that would be closed",
+					},
+				},
 				Action: func(cCtx *cli.Context) error {
 					err := settings.Load(cCtx)
 					if err != nil {
@@ -43,8 +117,39 @@ func main() {
 					if err != nil {
 						return err
 					}
-					
-					return reporter.Verify()
+
+					opts, err := internal.NewStaleOptions(&settings.Config, cCtx.Int("days"), cCtx.Bool("dry-run"))
+					if err != nil {
+						return err
+					}
+
+					return internal.CloseStale(reporter, opts)
+				},
+			},
+			{
+				Name:  "flush",
+				Usage: "deliver reports saved in spool_dir after the tracker failed to accept them",
+				Action: func(cCtx *cli.Context) error {
+					err := settings.Load(cCtx)
+					if err != nil {
+						return err
+					}
+
+					spool := settings.Config.GetSpool()
+					if spool == nil {
+						return errors.New("no spool_dir specified in config")
+					}
+
+					reporter := settings.Config.GetReporter()
+					err = reporter.Init()
+					if err != nil {
+						return err
+					}
+
+					flushed, err := internal.FlushSpool(reporter, spool)
+					log.Printf("[rspec-sanity] Flushed %d spooled report(s)", flushed)
+
+					return err
 				},
 			},
 			{
@@ -67,26 +172,76 @@ func main() {
 
 					runnerStatus := runner.Run()
 
-					if runnerStatus.HasFlakies() {
-						// we will crash app on error here; otherwise debugging potential
-						// issues in reporter itself will be nightmare
-						reporter := settings.Config.GetReporter()
-						err = reporter.Init()
+					if output := internal.NewGithubActionsOutput(); output != nil {
+						err = output.Write(&runnerStatus)
 
 						if err != nil {
-							return err
+							log.Printf("[github-actions] Failed to write job summary: %v", err)
 						}
+					}
 
-						err = internal.ReportFlakies(reporter, runnerStatus.FlakyExamples)
+					var groups []internal.FlakyGroup
+
+					if runnerStatus.HasFlakies() {
+						groups, err = settings.Config.GroupFlakies(runnerStatus.FlakyExamples)
 
 						if err != nil {
 							return err
 						}
-
 					} else {
 						log.Println("[rspec-sanity] No flaky examples found")
 					}
 
+					reporter := settings.Config.GetReporter()
+
+					// reporters are finalized on clean runs too (eg. to write an
+					// empty report or to update the pull request comment)
+					if len(groups) > 0 || internal.IsFinalizer(reporter) {
+						spool := settings.Config.GetSpool()
+
+						// we will crash app on error here (unless reports can be spooled);
+						// otherwise debugging potential issues in reporter itself will be nightmare
+						err = reporter.Init()
+
+						if err != nil {
+							// eg. bad credentials would fail on flush again
+							if spool == nil || !internal.IsTransientError(err) {
+								return err
+							}
+
+							log.Printf("[rspec-sanity] Failed to initialize reporter: %v", err)
+							if err = spool.SaveAll(groups); err != nil {
+								return err
+							}
+
+							os.Exit(runnerStatus.StatusCode)
+						}
+
+						results, reportErr := internal.ReportFlakies(reporter, groups, settings.Config.Concurrency)
+
+						for _, result := range results {
+							if result.Succeeded() {
+								log.Printf("[rspec-sanity] Reported %s", result.Group.Key)
+							} else {
+								log.Printf("[rspec-sanity] Failed to report %s: %v", result.Group.Key, result.Error)
+							}
+						}
+
+						// spooled reports are delivered later with `flush`, groups
+						// failing permanently still fail the command
+						if spool != nil && reportErr != nil {
+							reportErr = spool.SaveFailed(results)
+						}
+
+						// finalize even if some groups failed, so whatever got
+						// reported is still summarized
+						err = internal.FinalizeReporter(reporter, &runnerStatus)
+
+						if err = errors.Join(reportErr, err); err != nil {
+							return err
+						}
+					}
+
 					// if nothing failed during reporting - propagate exit code from rspec
 					os.Exit(runnerStatus.StatusCode)
 					return nil